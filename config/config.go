@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -15,11 +16,47 @@ type Config struct {
 	DBSSLMode  string
 	JWTSecret  string
 	JWTExpiry  int // in hours
+	PINExpiry  int // in minutes
 	ServerPort string
+
+	PasswordMinLength     int
+	PasswordRequireUpper  bool
+	PasswordRequireLower  bool
+	PasswordRequireDigit  bool
+	PasswordRequireSymbol bool
+	PasswordHistory       int // number of recent passwords that cannot be reused
+
+	SoftDeleteRetentionDays int // soft-deleted records older than this can be purged
+
+	OrderNumberTemplate string // e.g. INV/{STORE}/{YYYY}/{SEQ:6}
+	OrderNumberReset    string // never, yearly, monthly or daily
+
+	IdempotencyKeyTTL int // in hours
+
+	MultiTenant      bool
+	TenantBaseDomain string // tenants are resolved from <slug>.<base domain> when set
+	PlatformAdminKey string // required to manage tenants; tenant endpoints are disabled when empty
+
+	RequireCashierTerminal bool // cashiers may only call the API from a registered terminal
+
+	UserMaxCredentialAttempts     int // wrong PINs or supervisor credentials before a user is locked out
+	TerminalMaxCredentialAttempts int // wrong PINs or supervisor credentials before a terminal is locked out
+	CredentialLockoutMinutes      int
+
+	BarcodeWeightPrefixes []string // EAN-13 prefixes (20-29) of in-store barcodes that encode a weight in grams
+	BarcodePricePrefixes  []string // EAN-13 prefixes (20-29) of in-store barcodes that encode a price
 }
 
 func LoadConfig() (*Config, error) {
 	jwtExpiry, _ := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
+	pinExpiry, _ := strconv.Atoi(getEnv("PIN_TOKEN_EXPIRY_MINUTES", "15"))
+	passwordMinLength, _ := strconv.Atoi(getEnv("PASSWORD_MIN_LENGTH", "8"))
+	passwordHistory, _ := strconv.Atoi(getEnv("PASSWORD_HISTORY", "3"))
+	retentionDays, _ := strconv.Atoi(getEnv("SOFT_DELETE_RETENTION_DAYS", "30"))
+	idempotencyKeyTTL, _ := strconv.Atoi(getEnv("IDEMPOTENCY_KEY_TTL_HOURS", "24"))
+	userMaxCredentialAttempts, _ := strconv.Atoi(getEnv("USER_MAX_CREDENTIAL_ATTEMPTS", "5"))
+	terminalMaxCredentialAttempts, _ := strconv.Atoi(getEnv("TERMINAL_MAX_CREDENTIAL_ATTEMPTS", "20"))
+	credentialLockoutMinutes, _ := strconv.Atoi(getEnv("CREDENTIAL_LOCKOUT_MINUTES", "15"))
 
 	// Require critical secrets - no insecure defaults
 	jwtSecret := os.Getenv("JWT_SECRET")
@@ -47,6 +84,18 @@ func LoadConfig() (*Config, error) {
 		return nil, errors.New("DB_NAME environment variable is required")
 	}
 
+	orderNumberTemplate := getEnv("ORDER_NUMBER_TEMPLATE", "SO-{YYYY}{MM}{DD}-{SEQ:4}")
+	orderNumberReset := getEnv("ORDER_NUMBER_RESET", "daily")
+	if err := validateOrderNumbering(orderNumberTemplate, orderNumberReset); err != nil {
+		return nil, err
+	}
+
+	barcodeWeightPrefixes := splitList(getEnv("BARCODE_WEIGHT_PREFIXES", "20,21,22,23,24"))
+	barcodePricePrefixes := splitList(getEnv("BARCODE_PRICE_PREFIXES", "25,26,27,28,29"))
+	if err := validateBarcodePrefixes(barcodeWeightPrefixes, barcodePricePrefixes); err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		DBHost:     dbHost,
 		DBPort:     getEnv("DB_PORT", "5432"),
@@ -56,13 +105,107 @@ func LoadConfig() (*Config, error) {
 		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		JWTSecret:  jwtSecret,
 		JWTExpiry:  jwtExpiry,
+		PINExpiry:  pinExpiry,
 		ServerPort: getEnv("SERVER_PORT", "8080"),
+
+		PasswordMinLength:     passwordMinLength,
+		PasswordRequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+		PasswordRequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+		PasswordRequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordHistory:       passwordHistory,
+
+		SoftDeleteRetentionDays: retentionDays,
+
+		OrderNumberTemplate: orderNumberTemplate,
+		OrderNumberReset:    orderNumberReset,
+
+		IdempotencyKeyTTL: idempotencyKeyTTL,
+
+		MultiTenant:      getEnvBool("MULTI_TENANT", false),
+		TenantBaseDomain: getEnv("TENANT_BASE_DOMAIN", ""),
+		PlatformAdminKey: os.Getenv("PLATFORM_ADMIN_KEY"),
+
+		RequireCashierTerminal: getEnvBool("REQUIRE_CASHIER_TERMINAL", false),
+
+		UserMaxCredentialAttempts:     userMaxCredentialAttempts,
+		TerminalMaxCredentialAttempts: terminalMaxCredentialAttempts,
+		CredentialLockoutMinutes:      credentialLockoutMinutes,
+
+		BarcodeWeightPrefixes: barcodeWeightPrefixes,
+		BarcodePricePrefixes:  barcodePricePrefixes,
 	}, nil
 }
 
+// validateBarcodePrefixes makes sure variable measure prefixes are in the GS1 in-store
+// range 20-29 and that no prefix encodes both a weight and a price
+func validateBarcodePrefixes(weight, price []string) error {
+	seen := make(map[string]bool)
+	for _, prefix := range append(append([]string{}, weight...), price...) {
+		if len(prefix) != 2 || prefix[0] != '2' || prefix[1] < '0' || prefix[1] > '9' {
+			return errors.New("BARCODE_WEIGHT_PREFIXES and BARCODE_PRICE_PREFIXES may only contain 20 to 29")
+		}
+		if seen[prefix] {
+			return errors.New("barcode prefix " + prefix + " is listed more than once")
+		}
+		seen[prefix] = true
+	}
+	return nil
+}
+
+// validateOrderNumbering makes sure numbers stay unique across sequence resets:
+// the template must contain the sequence and every date part the reset period depends on
+func validateOrderNumbering(template, reset string) error {
+	if !strings.Contains(template, "{SEQ") {
+		return errors.New("ORDER_NUMBER_TEMPLATE must contain a {SEQ} token")
+	}
+
+	hasYear := strings.Contains(template, "{YYYY}") || strings.Contains(template, "{YY}")
+	hasMonth := strings.Contains(template, "{MM}")
+	hasDay := strings.Contains(template, "{DD}")
+
+	switch reset {
+	case "never":
+	case "yearly":
+		if !hasYear {
+			return errors.New("ORDER_NUMBER_TEMPLATE must contain {YYYY} or {YY} when ORDER_NUMBER_RESET is yearly")
+		}
+	case "monthly":
+		if !hasYear || !hasMonth {
+			return errors.New("ORDER_NUMBER_TEMPLATE must contain the year and {MM} when ORDER_NUMBER_RESET is monthly")
+		}
+	case "daily":
+		if !hasYear || !hasMonth || !hasDay {
+			return errors.New("ORDER_NUMBER_TEMPLATE must contain the year, {MM} and {DD} when ORDER_NUMBER_RESET is daily")
+		}
+	default:
+		return errors.New("ORDER_NUMBER_RESET must be one of never, yearly, monthly, daily")
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// splitList splits a comma separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -26,6 +26,10 @@ func Connect(cfg *config.Config) *gorm.DB {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	if err := RegisterTenantScope(db); err != nil {
+		log.Fatalf("Failed to register tenant scope: %v", err)
+	}
+
 	DB = db
 	log.Println("Database connection established")
 	return db
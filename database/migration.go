@@ -1,34 +1,256 @@
 package database
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"time"
 
 	"interview-user/models"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
+// tenantModels are the models owned by a tenant, in migration order
+var tenantModels = []interface{}{
+	&models.Outlet{},
+	&models.User{},
+	&models.SaleOrder{},
+	&models.SaleOrderItem{},
+	&models.SaleOrderRevision{},
+	&models.OrderSequence{},
+	&models.Terminal{},
+	&models.RoleDefinition{},
+	&models.AuditLog{},
+	&models.Category{},
+	&models.Product{},
+	&models.ProductVariant{},
+	&models.ModifierGroup{},
+	&models.Modifier{},
+	&models.SaleOrderItemModifier{},
+	&models.StockMovement{},
+	&models.Supplier{},
+	&models.PurchaseOrder{},
+	&models.PurchaseOrderItem{},
+	&models.GoodsReceipt{},
+	&models.GoodsReceiptItem{},
+	&models.StockTake{},
+	&models.StockTakeItem{},
+}
+
+// globalUniqueIndexes were replaced by per-tenant unique indexes
+var globalUniqueIndexes = []string{
+	"idx_users_username",
+	"idx_sale_orders_order_number",
+	"idx_sale_orders_client_id",
+	"idx_outlets_code",
+	"idx_roles_name",
+	"idx_order_sequence_scope_period",
+}
+
 func Migrate(db *gorm.DB) error {
 	log.Println("Running database migrations...")
 
-	err := db.AutoMigrate(
-		&models.User{},
-		&models.SaleOrder{},
-		&models.SaleOrderItem{},
-	)
+	if err := db.AutoMigrate(&models.Tenant{}); err != nil {
+		return err
+	}
 
+	defaultTenant, err := ensureDefaultTenant(db)
 	if err != nil {
 		return err
 	}
 
+	if err := addTenantColumns(db, defaultTenant.ID); err != nil {
+		return err
+	}
+
+	for _, index := range globalUniqueIndexes {
+		if err := db.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			return err
+		}
+	}
+
+	allModels := append(tenantModels,
+		&models.PasswordHistory{},
+		&models.RolePermission{},
+		&models.IdempotencyKey{},
+		&models.SchemaMigration{},
+	)
+	if err := db.AutoMigrate(allModels...); err != nil {
+		return err
+	}
+
+	if err := protectAuditLogs(db); err != nil {
+		return err
+	}
+
+	if err := forceDefaultPasswordChange(db.WithContext(WithTenant(context.Background(), defaultTenant.ID))); err != nil {
+		return err
+	}
+
+	for _, migration := range dataMigrations {
+		if err := runOnce(db, migration.name, migration.run); err != nil {
+			return err
+		}
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
 
+// ensureDefaultTenant creates the tenant that owns data in single-tenant mode
+func ensureDefaultTenant(db *gorm.DB) (*models.Tenant, error) {
+	tenant := models.Tenant{Slug: models.DefaultTenantSlug, Name: "Default", IsActive: true}
+	if err := db.Where("slug = ?", tenant.Slug).FirstOrCreate(&tenant).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// addTenantColumns assigns rows created before tenants existed to the default tenant
+func addTenantColumns(db *gorm.DB, defaultTenantID uint) error {
+	for _, model := range tenantModels {
+		if !db.Migrator().HasTable(model) || db.Migrator().HasColumn(model, "TenantID") {
+			continue
+		}
+
+		table := model.(schema.Tabler).TableName()
+		statements := []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN tenant_id bigint NOT NULL DEFAULT %d", table, defaultTenantID),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN tenant_id DROP DEFAULT", table),
+		}
+		for _, statement := range statements {
+			if err := db.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// defaultPasswords are the public credentials of the seeded accounts
+var defaultPasswords = map[string]string{
+	"owner":   "owner123",
+	"cashier": "cashier123",
+}
+
+// forceDefaultPasswordChange flags seeded accounts that still use their public password.
+// Databases seeded before the flag existed got false for every user.
+func forceDefaultPasswordChange(db *gorm.DB) error {
+	var users []models.User
+	if err := db.Where("username IN ? AND must_change_password = ?", []string{"owner", "cashier"}, false).Find(&users).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(defaultPasswords[user.Username])) != nil {
+			continue
+		}
+		if err := db.Model(&user).Updates(map[string]interface{}{
+			"must_change_password": true,
+			"version":              gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		log.Printf("User %q still uses the default password and must change it on next login", user.Username)
+	}
+	return nil
+}
+
+// dataMigrations change existing rows once, in order. They run across every tenant,
+// so they must not rely on the tenant scope of tenant-owned models.
+var dataMigrations = []struct {
+	name string
+	run  func(tx *gorm.DB) error
+}{
+	{"remove_cashier_sale_order_void", removeCashierVoid},
+	{"grant_cashier_stock_take_count", grantCashierStockTakeCount},
+	{"backfill_sale_order_item_list_price", backfillListPrice},
+}
+
+// runOnce applies a data migration unless it is already recorded in schema_migrations
+func runOnce(db *gorm.DB, name string, run func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.SchemaMigration{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := run(tx); err != nil {
+			return err
+		}
+		log.Printf("Data migration %q applied", name)
+		return tx.Create(&models.SchemaMigration{Name: name, AppliedAt: time.Now()}).Error
+	})
+}
+
+// removeCashierVoid takes sale_order.void away from existing cashier system roles;
+// voiding now needs a supervisor override, but SeedRoles only applies that to new tenants
+func removeCashierVoid(tx *gorm.DB) error {
+	return tx.Exec(
+		"DELETE FROM role_permissions WHERE permission = ? AND role_id IN (SELECT id FROM roles WHERE name = ? AND is_system = ?)",
+		models.PermSaleOrderVoid, models.RoleCashier, true,
+	).Error
+}
+
+// grantCashierStockTakeCount gives existing cashier system roles stock_take.count,
+// which SeedRoles only grants to tenants created after stock takes existed
+func grantCashierStockTakeCount(tx *gorm.DB) error {
+	return tx.Exec(
+		`INSERT INTO role_permissions (role_id, permission)
+		SELECT roles.id, ? FROM roles
+		WHERE roles.name = ? AND roles.is_system = ?
+		AND NOT EXISTS (SELECT 1 FROM role_permissions WHERE role_permissions.role_id = roles.id AND role_permissions.permission = ?)`,
+		models.PermStockTakeCount, models.RoleCashier, true, models.PermStockTakeCount,
+	).Error
+}
+
+// backfillListPrice lists items sold before list prices were snapshotted at the price they
+// were sold for, since their catalog price at the time is unknown
+func backfillListPrice(tx *gorm.DB) error {
+	return tx.Exec("UPDATE sale_order_items SET list_price = unit_price WHERE list_price = 0").Error
+}
+
+// protectAuditLogs installs a trigger that makes the audit_logs table append-only
+func protectAuditLogs(db *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+		`CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs
+		FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Seed creates the system roles and default users of the default tenant
 func Seed(db *gorm.DB) error {
 	log.Println("Seeding database...")
 
+	var tenant models.Tenant
+	if err := db.Where("slug = ?", models.DefaultTenantSlug).First(&tenant).Error; err != nil {
+		return err
+	}
+	db = db.WithContext(WithTenant(context.Background(), tenant.ID))
+
+	if err := SeedRoles(db); err != nil {
+		return err
+	}
+
 	// Check if owner already exists
 	var count int64
 	db.Model(&models.User{}).Where("role = ?", models.RoleOwner).Count(&count)
@@ -37,14 +259,16 @@ func Seed(db *gorm.DB) error {
 		return nil
 	}
 
-	// Create default owner
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("owner123"), bcrypt.DefaultCost)
+	// Create default owner. Default credentials are public, so seeded
+	// accounts must change their password on first login.
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(defaultPasswords["owner"]), bcrypt.DefaultCost)
 	owner := models.User{
-		Username: "owner",
-		Password: string(hashedPassword),
-		Name:     "System Owner",
-		Role:     models.RoleOwner,
-		IsActive: true,
+		Username:           "owner",
+		Password:           string(hashedPassword),
+		Name:               "System Owner",
+		Role:               models.RoleOwner,
+		IsActive:           true,
+		MustChangePassword: true,
 	}
 
 	if err := db.Create(&owner).Error; err != nil {
@@ -52,13 +276,14 @@ func Seed(db *gorm.DB) error {
 	}
 
 	// Create default cashier
-	hashedPassword, _ = bcrypt.GenerateFromPassword([]byte("cashier123"), bcrypt.DefaultCost)
+	hashedPassword, _ = bcrypt.GenerateFromPassword([]byte(defaultPasswords["cashier"]), bcrypt.DefaultCost)
 	cashier := models.User{
-		Username: "cashier",
-		Password: string(hashedPassword),
-		Name:     "Default Cashier",
-		Role:     models.RoleCashier,
-		IsActive: true,
+		Username:           "cashier",
+		Password:           string(hashedPassword),
+		Name:               "Default Cashier",
+		Role:               models.RoleCashier,
+		IsActive:           true,
+		MustChangePassword: true,
 	}
 
 	if err := db.Create(&cashier).Error; err != nil {
@@ -69,6 +294,40 @@ func Seed(db *gorm.DB) error {
 	log.Println("Default users created:")
 	log.Println("  Owner: username=owner, password=owner123")
 	log.Println("  Cashier: username=cashier, password=cashier123")
+	log.Println("Both accounts must change their password on first login")
+
+	return nil
+}
+
+// SeedRoles creates the system roles with their default permissions if they don't exist yet.
+// Roles are created for the tenant of db's context.
+func SeedRoles(db *gorm.DB) error {
+	descriptions := map[models.Role]string{
+		models.RoleOwner:   "Full access to every resource",
+		models.RoleCashier: "Point of sale operations",
+	}
+
+	for _, name := range []models.Role{models.RoleOwner, models.RoleCashier} {
+		var count int64
+		db.Model(&models.RoleDefinition{}).Where("name = ?", name).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		role := models.RoleDefinition{
+			Name:        name,
+			Description: descriptions[name],
+			IsSystem:    true,
+		}
+		for _, permission := range models.DefaultRolePermissions[name] {
+			role.Permissions = append(role.Permissions, models.RolePermission{Permission: permission})
+		}
+
+		if err := db.Create(&role).Error; err != nil {
+			return err
+		}
+		log.Printf("System role %q created", name)
+	}
 
 	return nil
 }
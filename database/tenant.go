@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrTenantRequired is returned for queries on tenant-owned models whose context
+// carries no tenant, so a forgotten WithContext can never leak another tenant's data
+var ErrTenantRequired = errors.New("tenant is required for this query")
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context whose queries are limited to tenantID
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant stored by WithTenant
+func TenantFromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	tenantID, ok := ctx.Value(tenantContextKey{}).(uint)
+	return tenantID, ok && tenantID != 0
+}
+
+// RegisterTenantScope installs callbacks that scope every query, update and delete on a
+// model with a TenantID field to the tenant of the statement context, and fill TenantID
+// on create. Models without the field, such as tenants themselves, are not affected.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", assignTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("tenant:row", scopeTenant)
+}
+
+func tenantField(db *gorm.DB) *schema.Field {
+	if db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField("TenantID")
+}
+
+func scopeTenant(db *gorm.DB) {
+	if db.Error != nil || tenantField(db) == nil {
+		return
+	}
+
+	tenantID, ok := TenantFromContext(db.Statement.Context)
+	if !ok {
+		db.AddError(ErrTenantRequired)
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "tenant_id"}, Value: tenantID},
+	}})
+}
+
+func assignTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if db.Error != nil || field == nil {
+		return
+	}
+
+	tenantID, ok := TenantFromContext(db.Statement.Context)
+	if !ok {
+		db.AddError(ErrTenantRequired)
+		return
+	}
+
+	ctx := db.Statement.Context
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := field.Set(ctx, reflect.Indirect(value.Index(i)), tenantID); err != nil {
+				db.AddError(err)
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(ctx, value, tenantID); err != nil {
+			db.AddError(err)
+		}
+	}
+}
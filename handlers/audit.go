@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// redactedAuditFields are recorded as changed without revealing their values
+var redactedAuditFields = []string{"password", "pin"}
+
+// recordAudit appends an audit log entry for the authenticated caller.
+// before and after are snapshots of the entity; either may be nil for creates and deletes.
+func recordAudit(db *gorm.DB, c *gin.Context, action, entityType string, entityID uint, before, after interface{}) error {
+	changes, err := utils.DiffJSON(before, after)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range redactedAuditFields {
+		if _, changed := changes[field]; changed {
+			changes[field] = utils.FieldChange{Before: "[REDACTED]", After: "[REDACTED]"}
+		}
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	entry := models.AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    data,
+		IPAddress:  c.ClientIP(),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		id := userID.(uint)
+		entry.ActorID = &id
+	}
+	if username, exists := c.Get("username"); exists {
+		entry.ActorUsername = username.(string)
+	}
+
+	return db.Create(&entry).Error
+}
+
+// userAuditState is the audited snapshot of a user. Credential hashes are included
+// so changes are detected, and redacted by recordAudit.
+func userAuditState(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"username":             user.Username,
+		"name":                 user.Name,
+		"role":                 user.Role,
+		"outlet_id":            user.OutletID,
+		"is_active":            user.IsActive,
+		"must_change_password": user.MustChangePassword,
+		"password":             user.Password,
+		"pin":                  user.PIN,
+	}
+}
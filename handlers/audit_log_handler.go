@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AuditLogHandler struct {
+	DB *gorm.DB
+}
+
+func NewAuditLogHandler(db *gorm.DB) *AuditLogHandler {
+	return &AuditLogHandler{DB: db}
+}
+
+// parseTimeQuery accepts either RFC3339 timestamps or plain dates (YYYY-MM-DD)
+func parseTimeQuery(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// GetAll returns audit log entries with pagination.
+// Supported filters: actor_id, action, entity_type, entity_id, from, to.
+func (h *AuditLogHandler) GetAll(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	query := h.DB.WithContext(c).Model(&models.AuditLog{})
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid actor_id")
+			return
+		}
+		query = query.Where("actor_id = ?", id)
+	}
+
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	if entityType := c.Query("entity_type"); entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	if entityID := c.Query("entity_id"); entityID != "" {
+		id, err := strconv.ParseUint(entityID, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid entity_id")
+			return
+		}
+		query = query.Where("entity_id = ?", id)
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := parseTimeQuery(from)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid from, use YYYY-MM-DD or RFC3339")
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := parseTimeQuery(to)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid to, use YYYY-MM-DD or RFC3339")
+			return
+		}
+		// A plain date includes the whole day
+		if len(to) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count audit logs")
+		return
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("created_at DESC, id DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&logs).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch audit logs")
+		return
+	}
+
+	utils.OKResponse(c, "Audit logs retrieved successfully", utils.PaginatedResponse{
+		Items:      logs,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
@@ -11,14 +11,18 @@ import (
 )
 
 type AuthHandler struct {
-	DB         *gorm.DB
-	JWTService *utils.JWTService
+	DB             *gorm.DB
+	JWTService     *utils.JWTService
+	PasswordPolicy utils.PasswordPolicy
+	Credentials    *utils.CredentialThrottle
 }
 
-func NewAuthHandler(db *gorm.DB, jwtService *utils.JWTService) *AuthHandler {
+func NewAuthHandler(db *gorm.DB, jwtService *utils.JWTService, passwordPolicy utils.PasswordPolicy, credentials *utils.CredentialThrottle) *AuthHandler {
 	return &AuthHandler{
-		DB:         db,
-		JWTService: jwtService,
+		DB:             db,
+		JWTService:     jwtService,
+		PasswordPolicy: passwordPolicy,
+		Credentials:    credentials,
 	}
 }
 
@@ -27,16 +31,27 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type PINLoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	PIN      string `json:"pin" binding:"required,number,min=4,max=6"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
 type LoginResponse struct {
 	Token string       `json:"token"`
 	User  UserResponse `json:"user"`
 }
 
 type UserResponse struct {
-	ID       uint        `json:"id"`
-	Username string      `json:"username"`
-	Name     string      `json:"name"`
-	Role     models.Role `json:"role"`
+	ID                 uint        `json:"id"`
+	Username           string      `json:"username"`
+	Name               string      `json:"name"`
+	Role               models.Role `json:"role"`
+	MustChangePassword bool        `json:"must_change_password"`
 }
 
 // Login handles user authentication
@@ -48,7 +63,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := h.DB.Where("username = ? AND is_active = ?", req.Username, true).First(&user).Error; err != nil {
+	if err := h.DB.WithContext(c).Where("username = ? AND is_active = ?", req.Username, true).First(&user).Error; err != nil {
 		utils.UnauthorizedResponse(c, "Invalid username or password")
 		return
 	}
@@ -67,10 +82,132 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	utils.OKResponse(c, "Login successful", LoginResponse{
 		Token: token,
 		User: UserResponse{
-			ID:       user.ID,
-			Username: user.Username,
-			Name:     user.Name,
-			Role:     user.Role,
+			ID:                 user.ID,
+			Username:           user.Username,
+			Name:               user.Name,
+			Role:               user.Role,
+			MustChangePassword: user.MustChangePassword,
+		},
+	})
+}
+
+// PINLogin authenticates a user by PIN from a registered terminal and issues a short-lived token.
+// Repeated wrong PINs lock out the username and the terminal for a while.
+func (h *AuthHandler) PINLogin(c *gin.Context) {
+	var req PINLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	tenantID := c.GetUint("tenant_id")
+	terminalID := c.GetUint("terminal_id")
+
+	if !checkCredentialThrottle(c, h.Credentials, tenantID, req.Username, terminalID) {
+		return
+	}
+
+	var user models.User
+	if err := h.DB.WithContext(c).Where("username = ? AND is_active = ?", req.Username, true).First(&user).Error; err != nil ||
+		user.PIN == "" ||
+		bcrypt.CompareHashAndPassword([]byte(user.PIN), []byte(req.PIN)) != nil {
+		h.Credentials.Fail(tenantID, req.Username, terminalID)
+		utils.UnauthorizedResponse(c, "Invalid username or PIN")
+		return
+	}
+	h.Credentials.Succeed(tenantID, req.Username)
+
+	token, err := h.JWTService.GeneratePINToken(&user, terminalID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to generate token")
+		return
+	}
+
+	utils.OKResponse(c, "Login successful", LoginResponse{
+		Token: token,
+		User: UserResponse{
+			ID:                 user.ID,
+			Username:           user.Username,
+			Name:               user.Name,
+			Role:               user.Role,
+			MustChangePassword: user.MustChangePassword,
+		},
+	})
+}
+
+// ChangePassword replaces the current user's password and issues a fresh token.
+// It is the only endpoint available to users flagged with must_change_password.
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	var user models.User
+	if err := h.DB.WithContext(c).Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		utils.UnauthorizedResponse(c, "User not found")
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		utils.BadRequestResponse(c, "Current password is incorrect")
+		return
+	}
+
+	message, err := validateNewPassword(h.DB.WithContext(c), h.PasswordPolicy, &user, req.NewPassword)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to validate password")
+		return
+	}
+	if message != "" {
+		utils.BadRequestResponse(c, message)
+		return
+	}
+
+	before := userAuditState(&user)
+
+	err = h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := setUserPassword(tx, &user, req.NewPassword); err != nil {
+			return err
+		}
+		user.MustChangePassword = false
+
+		if err := updateVersioned(tx, &user, &user.Version); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityUser, user.ID, before, userAuditState(&user))
+	})
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "Account was modified by another request, try again")
+		return
+	}
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update password")
+		return
+	}
+
+	// The old token still carries the must_change_password flag
+	if token, exists := c.Get("token"); exists {
+		middleware.BlacklistToken(token.(string))
+	}
+
+	token, err := h.JWTService.GenerateToken(&user)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to generate token")
+		return
+	}
+
+	utils.OKResponse(c, "Password changed successfully", LoginResponse{
+		Token: token,
+		User: UserResponse{
+			ID:                 user.ID,
+			Username:           user.Username,
+			Name:               user.Name,
+			Role:               user.Role,
+			MustChangePassword: user.MustChangePassword,
 		},
 	})
 }
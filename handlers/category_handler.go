@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"strconv"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CategoryHandler struct {
+	DB *gorm.DB
+}
+
+func NewCategoryHandler(db *gorm.DB) *CategoryHandler {
+	return &CategoryHandler{DB: db}
+}
+
+type CreateCategoryRequest struct {
+	Name            string `json:"name" binding:"required,max=255"`
+	ParentID        *uint  `json:"parent_id"`
+	SortOrder       int    `json:"sort_order"`
+	HiddenOutletIDs []uint `json:"hidden_outlet_ids"`
+}
+
+// UpdateCategoryRequest changes a category; "parent_id": 0 moves it to the top level and
+// hidden_outlet_ids, when sent, replaces the outlets the category is hidden at.
+type UpdateCategoryRequest struct {
+	Name            *string `json:"name" binding:"omitempty,min=1,max=255"`
+	ParentID        *uint   `json:"parent_id"`
+	SortOrder       *int    `json:"sort_order"`
+	HiddenOutletIDs *[]uint `json:"hidden_outlet_ids"`
+}
+
+// GetAll returns all categories as a flat list ordered for display; use parent_id to
+// build the tree or GET /menu for the ready-made menu
+func (h *CategoryHandler) GetAll(c *gin.Context) {
+	var categories []models.Category
+	if err := h.DB.WithContext(c).Preload("HiddenOutlets").Order("sort_order ASC, name ASC").Find(&categories).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch categories")
+		return
+	}
+
+	utils.OKResponse(c, "Categories retrieved successfully", categories)
+}
+
+// GetByID returns a category by ID
+func (h *CategoryHandler) GetByID(c *gin.Context) {
+	category, ok := h.findCategory(c)
+	if !ok {
+		return
+	}
+
+	utils.OKResponse(c, "Category retrieved successfully", category)
+}
+
+// Create creates a new category
+func (h *CategoryHandler) Create(c *gin.Context) {
+	var req CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	parentID, ok := h.resolveParent(c, req.ParentID, 0)
+	if !ok {
+		return
+	}
+	hiddenOutlets, ok := h.resolveHiddenOutlets(c, req.HiddenOutletIDs)
+	if !ok {
+		return
+	}
+
+	category := models.Category{
+		ParentID:      parentID,
+		Name:          req.Name,
+		SortOrder:     req.SortOrder,
+		HiddenOutlets: hiddenOutlets,
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityCategory, category.ID, nil, categoryAuditState(&category))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create category")
+		return
+	}
+
+	utils.CreatedResponse(c, "Category created successfully", category)
+}
+
+// Update partially updates a category
+func (h *CategoryHandler) Update(c *gin.Context) {
+	category, ok := h.findCategory(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := categoryAuditState(category)
+
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.ParentID != nil {
+		parentID, ok := h.resolveParent(c, req.ParentID, category.ID)
+		if !ok {
+			return
+		}
+		category.ParentID = parentID
+	}
+	if req.SortOrder != nil {
+		category.SortOrder = *req.SortOrder
+	}
+	if req.HiddenOutletIDs != nil {
+		hiddenOutlets, ok := h.resolveHiddenOutlets(c, *req.HiddenOutletIDs)
+		if !ok {
+			return
+		}
+		category.HiddenOutlets = hiddenOutlets
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(category).Select("Name", "ParentID", "SortOrder").Updates(category).Error; err != nil {
+			return err
+		}
+		if req.HiddenOutletIDs != nil {
+			if err := tx.Model(category).Association("HiddenOutlets").Replace(category.HiddenOutlets); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityCategory, category.ID, before, categoryAuditState(category))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update category")
+		return
+	}
+
+	utils.OKResponse(c, "Category updated successfully", category)
+}
+
+// Delete soft deletes a category that has no products and no subcategories
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	category, ok := h.findCategory(c)
+	if !ok {
+		return
+	}
+
+	var products int64
+	if err := h.DB.WithContext(c).Model(&models.Product{}).Where("category_id = ?", category.ID).Count(&products).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count products")
+		return
+	}
+	if products > 0 {
+		utils.BadRequestResponse(c, "Category still has products")
+		return
+	}
+
+	var children int64
+	if err := h.DB.WithContext(c).Model(&models.Category{}).Where("parent_id = ?", category.ID).Count(&children).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count subcategories")
+		return
+	}
+	if children > 0 {
+		utils.BadRequestResponse(c, "Category still has subcategories")
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(category).Association("HiddenOutlets").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Delete(category).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntityCategory, category.ID, categoryAuditState(category), nil)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete category")
+		return
+	}
+
+	utils.OKResponse(c, "Category deleted successfully", nil)
+}
+
+// findCategory loads the category referenced by the :id param, writing an error response on failure
+func (h *CategoryHandler) findCategory(c *gin.Context) (*models.Category, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid category ID")
+		return nil, false
+	}
+
+	var category models.Category
+	if err := h.DB.WithContext(c).Preload("HiddenOutlets").First(&category, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Category not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch category")
+		return nil, false
+	}
+
+	return &category, true
+}
+
+// resolveParent checks the requested parent exists and is not the category itself or one
+// of its subcategories; 0 means a top-level category. It writes an error response and
+// returns false when the parent is not allowed.
+func (h *CategoryHandler) resolveParent(c *gin.Context, requested *uint, categoryID uint) (*uint, bool) {
+	if requested == nil || *requested == 0 {
+		return nil, true
+	}
+
+	var parent models.Category
+	if err := h.DB.WithContext(c).First(&parent, *requested).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.BadRequestResponse(c, "Parent category not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch parent category")
+		return nil, false
+	}
+
+	if categoryID != 0 {
+		subtree, err := categorySubtreeIDs(h.DB.WithContext(c), categoryID)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to fetch categories")
+			return nil, false
+		}
+		for _, id := range subtree {
+			if id == parent.ID {
+				utils.BadRequestResponse(c, "A category cannot be moved under itself or its subcategories")
+				return nil, false
+			}
+		}
+	}
+
+	return &parent.ID, true
+}
+
+// resolveHiddenOutlets loads the outlets a category is hidden at, writing an error
+// response and returning false when one of them does not exist
+func (h *CategoryHandler) resolveHiddenOutlets(c *gin.Context, ids []uint) ([]models.Outlet, bool) {
+	outlets := []models.Outlet{}
+	if len(ids) == 0 {
+		return outlets, true
+	}
+
+	if err := h.DB.WithContext(c).Where("id IN ?", ids).Find(&outlets).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch outlets")
+		return nil, false
+	}
+	if len(outlets) != len(uniqueIDs(ids)) {
+		utils.BadRequestResponse(c, "Outlet not found")
+		return nil, false
+	}
+
+	return outlets, true
+}
+
+// categoryAuditState is the audited representation of a category
+func categoryAuditState(category *models.Category) map[string]interface{} {
+	hiddenOutletIDs := make([]uint, 0, len(category.HiddenOutlets))
+	for _, outlet := range category.HiddenOutlets {
+		hiddenOutletIDs = append(hiddenOutletIDs, outlet.ID)
+	}
+	return map[string]interface{}{
+		"name":              category.Name,
+		"parent_id":         category.ParentID,
+		"sort_order":        category.SortOrder,
+		"hidden_outlet_ids": hiddenOutletIDs,
+	}
+}
+
+// categorySubtreeIDs returns the ID of a category followed by the IDs of all its
+// subcategories at any depth
+func categorySubtreeIDs(db *gorm.DB, id uint) ([]uint, error) {
+	var categories []models.Category
+	if err := db.Select("id", "parent_id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{id}
+	seen := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids, nil
+}
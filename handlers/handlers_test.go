@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"interview-user/database"
+	"interview-user/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testEnv is a tenant-scoped SQLite database with a signed-in owner. SQLite ignores
+// row locks, which is fine for the single-request tests run against it.
+type testEnv struct {
+	DB     *gorm.DB
+	Tenant models.Tenant
+	Owner  models.User
+	ctx    context.Context
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(0)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := database.RegisterTenantScope(db); err != nil {
+		t.Fatalf("register tenant scope: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.Tenant{},
+		&models.Outlet{},
+		&models.User{},
+		&models.RoleDefinition{},
+		&models.RolePermission{},
+		&models.Category{},
+		&models.Product{},
+		&models.ProductVariant{},
+		&models.ModifierGroup{},
+		&models.Modifier{},
+		&models.SaleOrder{},
+		&models.SaleOrderItem{},
+		&models.SaleOrderItemModifier{},
+		&models.SaleOrderRevision{},
+		&models.StockMovement{},
+		&models.AuditLog{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	env := &testEnv{DB: db, Tenant: models.Tenant{Slug: "test", Name: "Test", IsActive: true}}
+	if err := db.Create(&env.Tenant).Error; err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	env.ctx = database.WithTenant(context.Background(), env.Tenant.ID)
+
+	env.Owner = models.User{Username: "owner", Password: "x", Name: "Owner", Role: models.RoleOwner, IsActive: true}
+	env.create(t, &env.Owner)
+	return env
+}
+
+// tx returns a session scoped to the test tenant
+func (e *testEnv) tx() *gorm.DB {
+	return e.DB.WithContext(e.ctx)
+}
+
+func (e *testEnv) create(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := e.tx().Create(value).Error; err != nil {
+		t.Fatalf("create %T: %v", value, err)
+	}
+}
+
+// router returns an engine whose requests are authenticated as the owner
+func (e *testEnv) router() *gin.Engine {
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(func(c *gin.Context) {
+		c.Set("tenant_id", e.Tenant.ID)
+		c.Set("user_id", e.Owner.ID)
+		c.Set("username", e.Owner.Username)
+		c.Set("role", e.Owner.Role)
+		c.Request = c.Request.WithContext(database.WithTenant(c.Request.Context(), e.Tenant.ID))
+		c.Next()
+	})
+	return r
+}
+
+// do sends a JSON request with the given headers and returns the recorded response
+func do(r http.Handler, method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"sort"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MenuHandler struct {
+	DB *gorm.DB
+}
+
+func NewMenuHandler(db *gorm.DB) *MenuHandler {
+	return &MenuHandler{DB: db}
+}
+
+// MenuCategory is a category of the menu tree with its subcategories and active products
+type MenuCategory struct {
+	ID         uint             `json:"id"`
+	Name       string           `json:"name"`
+	SortOrder  int              `json:"sort_order"`
+	Categories []MenuCategory   `json:"categories"`
+	Products   []models.Product `json:"products"`
+}
+
+type MenuResponse struct {
+	OutletID      *uint            `json:"outlet_id"`
+	Categories    []MenuCategory   `json:"categories"`
+	Uncategorized []models.Product `json:"uncategorized"`
+}
+
+// GetMenu returns the category tree with the active products, their active variants and
+// modifier groups, as shown at the caller's outlet. Users with outlet.all see every
+// category unless they pick an outlet with ?outlet_id=. Categories hidden at the outlet
+// are left out with their subcategories, and so are categories without any product.
+func (h *MenuHandler) GetMenu(c *gin.Context) {
+	outlets, ok := listOutletScope(c, h.DB.WithContext(c))
+	if !ok {
+		return
+	}
+
+	var categories []models.Category
+	if err := h.DB.WithContext(c).Preload("HiddenOutlets").Order("sort_order ASC, name ASC").Find(&categories).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch categories")
+		return
+	}
+
+	var products []models.Product
+	if err := h.DB.WithContext(c).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_active = ?", true).Order("id ASC")
+		}).
+		Preload("ModifierGroups").
+		Preload("ModifierGroups.Modifiers", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_active = ?", true).Order("id ASC")
+		}).
+		Where("is_active = ?", true).
+		Order("name ASC").
+		Find(&products).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch products")
+		return
+	}
+	for i := range products {
+		sort.Slice(products[i].ModifierGroups, func(a, b int) bool {
+			return products[i].ModifierGroups[a].ID < products[i].ModifierGroups[b].ID
+		})
+	}
+
+	response := MenuResponse{
+		Categories:    []MenuCategory{},
+		Uncategorized: []models.Product{},
+	}
+	if !outlets.all {
+		response.OutletID = outlets.outletID
+	}
+
+	children := make(map[uint][]models.Category)
+	var roots []models.Category
+	for _, category := range categories {
+		if response.OutletID != nil && hiddenAtOutlet(category, *response.OutletID) {
+			continue
+		}
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	productsByCategory := make(map[uint][]models.Product)
+	for _, product := range products {
+		if product.CategoryID == nil {
+			response.Uncategorized = append(response.Uncategorized, product)
+		} else {
+			productsByCategory[*product.CategoryID] = append(productsByCategory[*product.CategoryID], product)
+		}
+	}
+
+	for _, root := range roots {
+		if node, ok := buildMenuCategory(root, children, productsByCategory); ok {
+			response.Categories = append(response.Categories, node)
+		}
+	}
+
+	utils.OKResponse(c, "Menu retrieved successfully", response)
+}
+
+// buildMenuCategory builds the menu node of a category, reporting false when neither the
+// category nor any of its subcategories has a product
+func buildMenuCategory(category models.Category, children map[uint][]models.Category, products map[uint][]models.Product) (MenuCategory, bool) {
+	node := MenuCategory{
+		ID:         category.ID,
+		Name:       category.Name,
+		SortOrder:  category.SortOrder,
+		Categories: []MenuCategory{},
+		Products:   products[category.ID],
+	}
+	if node.Products == nil {
+		node.Products = []models.Product{}
+	}
+
+	for _, child := range children[category.ID] {
+		if childNode, ok := buildMenuCategory(child, children, products); ok {
+			node.Categories = append(node.Categories, childNode)
+		}
+	}
+
+	return node, len(node.Products) > 0 || len(node.Categories) > 0
+}
+
+// hiddenAtOutlet reports whether a category is hidden at the outlet
+func hiddenAtOutlet(category models.Category, outletID uint) bool {
+	for _, outlet := range category.HiddenOutlets {
+		if outlet.ID == outletID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"strconv"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ModifierGroupHandler struct {
+	DB *gorm.DB
+}
+
+func NewModifierGroupHandler(db *gorm.DB) *ModifierGroupHandler {
+	return &ModifierGroupHandler{DB: db}
+}
+
+type CreateModifierRequest struct {
+	Name       string  `json:"name" binding:"required,max=255"`
+	PriceDelta float64 `json:"price_delta"`
+}
+
+type UpdateModifierRequest struct {
+	Name       *string  `json:"name" binding:"omitempty,min=1,max=255"`
+	PriceDelta *float64 `json:"price_delta"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+type CreateModifierGroupRequest struct {
+	Name          string                  `json:"name" binding:"required,max=255"`
+	MinSelections int                     `json:"min_selections" binding:"min=0"`
+	MaxSelections int                     `json:"max_selections" binding:"min=0"`
+	Modifiers     []CreateModifierRequest `json:"modifiers" binding:"dive"`
+}
+
+type UpdateModifierGroupRequest struct {
+	Name          *string `json:"name" binding:"omitempty,min=1,max=255"`
+	MinSelections *int    `json:"min_selections" binding:"omitempty,min=0"`
+	MaxSelections *int    `json:"max_selections" binding:"omitempty,min=0"`
+}
+
+// validSelections checks that the selection limits can be satisfied. A max of 0 means no limit.
+func validSelections(min, max int) bool {
+	return max == 0 || min <= max
+}
+
+// GetAll returns all modifier groups with their modifiers, ordered by name
+func (h *ModifierGroupHandler) GetAll(c *gin.Context) {
+	var groups []models.ModifierGroup
+	if err := h.DB.WithContext(c).Preload("Modifiers").Order("name ASC").Find(&groups).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch modifier groups")
+		return
+	}
+
+	utils.OKResponse(c, "Modifier groups retrieved successfully", groups)
+}
+
+// GetByID returns a modifier group with its modifiers
+func (h *ModifierGroupHandler) GetByID(c *gin.Context) {
+	group, ok := h.findModifierGroup(c)
+	if !ok {
+		return
+	}
+
+	utils.OKResponse(c, "Modifier group retrieved successfully", group)
+}
+
+// Create creates a modifier group together with its modifiers
+func (h *ModifierGroupHandler) Create(c *gin.Context) {
+	var req CreateModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if !validSelections(req.MinSelections, req.MaxSelections) {
+		utils.BadRequestResponse(c, "min_selections cannot exceed max_selections")
+		return
+	}
+
+	group := models.ModifierGroup{
+		Name:          req.Name,
+		MinSelections: req.MinSelections,
+		MaxSelections: req.MaxSelections,
+	}
+	for _, reqModifier := range req.Modifiers {
+		group.Modifiers = append(group.Modifiers, models.Modifier{
+			Name:       reqModifier.Name,
+			PriceDelta: reqModifier.PriceDelta,
+			IsActive:   true,
+		})
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityModifier, group.ID, nil, group)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create modifier group")
+		return
+	}
+
+	utils.CreatedResponse(c, "Modifier group created successfully", group)
+}
+
+// Update partially updates a modifier group
+func (h *ModifierGroupHandler) Update(c *gin.Context) {
+	group, ok := h.findModifierGroup(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := *group
+
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.MinSelections != nil {
+		group.MinSelections = *req.MinSelections
+	}
+	if req.MaxSelections != nil {
+		group.MaxSelections = *req.MaxSelections
+	}
+
+	if !validSelections(group.MinSelections, group.MaxSelections) {
+		utils.BadRequestResponse(c, "min_selections cannot exceed max_selections")
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Select("Name", "MinSelections", "MaxSelections").Updates(group).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityModifier, group.ID, before, group)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update modifier group")
+		return
+	}
+
+	utils.OKResponse(c, "Modifier group updated successfully", group)
+}
+
+// Delete soft deletes a modifier group with its modifiers and detaches it from all products.
+// Past orders keep the modifier names and prices.
+func (h *ModifierGroupHandler) Delete(c *gin.Context) {
+	group, ok := h.findModifierGroup(c)
+	if !ok {
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_modifier_groups WHERE modifier_group_id = ?", group.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("modifier_group_id = ?", group.ID).Delete(&models.Modifier{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(group).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntityModifier, group.ID, group, nil)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete modifier group")
+		return
+	}
+
+	utils.OKResponse(c, "Modifier group deleted successfully", nil)
+}
+
+// CreateModifier adds a modifier to a group
+func (h *ModifierGroupHandler) CreateModifier(c *gin.Context) {
+	group, ok := h.findModifierGroup(c)
+	if !ok {
+		return
+	}
+
+	var req CreateModifierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := *group
+	modifier := models.Modifier{
+		ModifierGroupID: group.ID,
+		Name:            req.Name,
+		PriceDelta:      req.PriceDelta,
+		IsActive:        true,
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&modifier).Error; err != nil {
+			return err
+		}
+		group.Modifiers = append(group.Modifiers, modifier)
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityModifier, group.ID, before, group)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create modifier")
+		return
+	}
+
+	utils.CreatedResponse(c, "Modifier created successfully", modifier)
+}
+
+// UpdateModifier partially updates a modifier
+func (h *ModifierGroupHandler) UpdateModifier(c *gin.Context) {
+	group, modifier, ok := h.findModifier(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateModifierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := *group
+	before.Modifiers = append([]models.Modifier(nil), group.Modifiers...)
+
+	if req.Name != nil {
+		modifier.Name = *req.Name
+	}
+	if req.PriceDelta != nil {
+		modifier.PriceDelta = *req.PriceDelta
+	}
+	if req.IsActive != nil {
+		modifier.IsActive = *req.IsActive
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(modifier).Select("Name", "PriceDelta", "IsActive").Updates(modifier).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityModifier, group.ID, before, group)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update modifier")
+		return
+	}
+
+	utils.OKResponse(c, "Modifier updated successfully", modifier)
+}
+
+// DeleteModifier soft deletes a modifier
+func (h *ModifierGroupHandler) DeleteModifier(c *gin.Context) {
+	group, modifier, ok := h.findModifier(c)
+	if !ok {
+		return
+	}
+
+	before := *group
+	before.Modifiers = append([]models.Modifier(nil), group.Modifiers...)
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(modifier).Error; err != nil {
+			return err
+		}
+		for i := range group.Modifiers {
+			if group.Modifiers[i].ID == modifier.ID {
+				group.Modifiers = append(group.Modifiers[:i], group.Modifiers[i+1:]...)
+				break
+			}
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityModifier, group.ID, before, group)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete modifier")
+		return
+	}
+
+	utils.OKResponse(c, "Modifier deleted successfully", nil)
+}
+
+// findModifierGroup loads the modifier group referenced by the :id param with its modifiers,
+// writing an error response on failure
+func (h *ModifierGroupHandler) findModifierGroup(c *gin.Context) (*models.ModifierGroup, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid modifier group ID")
+		return nil, false
+	}
+
+	var group models.ModifierGroup
+	if err := h.DB.WithContext(c).Preload("Modifiers").First(&group, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Modifier group not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch modifier group")
+		return nil, false
+	}
+
+	return &group, true
+}
+
+// findModifier loads the group and the modifier referenced by the :id and :modifierId params,
+// writing an error response on failure
+func (h *ModifierGroupHandler) findModifier(c *gin.Context) (*models.ModifierGroup, *models.Modifier, bool) {
+	group, ok := h.findModifierGroup(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	modifierID, err := strconv.ParseUint(c.Param("modifierId"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid modifier ID")
+		return nil, nil, false
+	}
+
+	for i := range group.Modifiers {
+		if group.Modifiers[i].ID == uint(modifierID) {
+			return group, &group.Modifiers[i], true
+		}
+	}
+
+	utils.NotFoundResponse(c, "Modifier not found")
+	return nil, nil, false
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"time"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultOrderSequenceScope numbers all orders from a single sequence
+const defaultOrderSequenceScope = "default"
+
+// unassignedStoreCode stands in for the store code of orders without an outlet
+const unassignedStoreCode = "HQ"
+
+// purchaseOrderSequenceScope numbers purchase orders apart from sale orders. It is
+// lowercase so it can never clash with an outlet code.
+const purchaseOrderSequenceScope = "purchase_order"
+
+// purchaseOrderNumbers is the fixed format of purchase order numbers
+var purchaseOrderNumbers = utils.OrderNumberFormat{
+	Template: "PO-{YYYY}{MM}-{SEQ:4}",
+	Reset:    utils.OrderNumberResetMonthly,
+}
+
+// stockTakeSequenceScope numbers stock take sessions
+const stockTakeSequenceScope = "stock_take"
+
+// stockTakeNumbers is the fixed format of stock take numbers
+var stockTakeNumbers = utils.OrderNumberFormat{
+	Template: "ST-{YYYY}{MM}-{SEQ:4}",
+	Reset:    utils.OrderNumberResetMonthly,
+}
+
+// nextOrderNumber issues the next order number for store. It must run inside the
+// transaction that creates the order: the sequence row stays locked until commit and a
+// rollback releases the number again, so issued numbers are gap-free.
+func nextOrderNumber(tx *gorm.DB, format utils.OrderNumberFormat, store string, now time.Time) (string, error) {
+	scope := defaultOrderSequenceScope
+	if format.PerStore() {
+		scope = store
+	}
+	return nextSequenceNumber(tx, format, scope, store, now)
+}
+
+// nextSequenceNumber increments the sequence of scope for the current period and renders it
+// with format. Like nextOrderNumber it must run inside the transaction using the number.
+func nextSequenceNumber(tx *gorm.DB, format utils.OrderNumberFormat, scope, store string, now time.Time) (string, error) {
+	sequence := models.OrderSequence{Scope: scope, Period: format.Period(now)}
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sequence).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("scope = ? AND period = ?", sequence.Scope, sequence.Period).
+		First(&sequence).Error; err != nil {
+		return "", err
+	}
+
+	sequence.LastValue++
+	if err := tx.Model(&sequence).Update("last_value", sequence.LastValue).Error; err != nil {
+		return "", err
+	}
+
+	return format.Format(now, store, sequence.LastValue), nil
+}
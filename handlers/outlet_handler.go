@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"regexp"
+	"strconv"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OutletHandler struct {
+	DB *gorm.DB
+}
+
+func NewOutletHandler(db *gorm.DB) *OutletHandler {
+	return &OutletHandler{DB: db}
+}
+
+// Outlet codes appear in order numbers, so they are kept short and uppercase
+var outletCodePattern = regexp.MustCompile(`^[A-Z0-9]{2,20}$`)
+
+type CreateOutletRequest struct {
+	Code    string `json:"code" binding:"required"`
+	Name    string `json:"name" binding:"required,max=255"`
+	Address string `json:"address"`
+}
+
+type UpdateOutletRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Address  *string `json:"address"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// GetAll returns all outlets with pagination
+func (h *OutletHandler) GetAll(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var total int64
+	var outlets []models.Outlet
+
+	if err := h.DB.WithContext(c).Model(&models.Outlet{}).Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count outlets")
+		return
+	}
+
+	if err := h.DB.WithContext(c).Order("code ASC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&outlets).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch outlets")
+		return
+	}
+
+	utils.OKResponse(c, "Outlets retrieved successfully", utils.PaginatedResponse{
+		Items:      outlets,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// GetByID returns an outlet by ID
+func (h *OutletHandler) GetByID(c *gin.Context) {
+	outlet, ok := h.findOutlet(c)
+	if !ok {
+		return
+	}
+
+	utils.OKResponse(c, "Outlet retrieved successfully", outlet)
+}
+
+// Create creates a new outlet
+func (h *OutletHandler) Create(c *gin.Context) {
+	var req CreateOutletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if !outletCodePattern.MatchString(req.Code) {
+		utils.BadRequestResponse(c, "code must be 2-20 uppercase letters or digits")
+		return
+	}
+
+	var existing models.Outlet
+	if err := h.DB.WithContext(c).Unscoped().Where("code = ?", req.Code).First(&existing).Error; err == nil {
+		utils.BadRequestResponse(c, "Outlet code already exists")
+		return
+	}
+
+	outlet := models.Outlet{
+		Code:     req.Code,
+		Name:     req.Name,
+		Address:  req.Address,
+		IsActive: true,
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&outlet).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityOutlet, outlet.ID, nil, outlet)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create outlet")
+		return
+	}
+
+	utils.CreatedResponse(c, "Outlet created successfully", outlet)
+}
+
+// Update changes an outlet's name, address or active flag. The code is fixed because
+// it is part of issued order numbers.
+func (h *OutletHandler) Update(c *gin.Context) {
+	outlet, ok := h.findOutlet(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateOutletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := *outlet
+
+	if req.Name != nil {
+		outlet.Name = *req.Name
+	}
+	if req.Address != nil {
+		outlet.Address = *req.Address
+	}
+	if req.IsActive != nil {
+		outlet.IsActive = *req.IsActive
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(outlet).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityOutlet, outlet.ID, before, outlet)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update outlet")
+		return
+	}
+
+	utils.OKResponse(c, "Outlet updated successfully", outlet)
+}
+
+// Delete soft deletes an outlet that no active user is assigned to
+func (h *OutletHandler) Delete(c *gin.Context) {
+	outlet, ok := h.findOutlet(c)
+	if !ok {
+		return
+	}
+
+	var assigned int64
+	if err := h.DB.WithContext(c).Model(&models.User{}).Where("outlet_id = ? AND is_active = ?", outlet.ID, true).Count(&assigned).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count users")
+		return
+	}
+	if assigned > 0 {
+		utils.BadRequestResponse(c, "Outlet still has active users")
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(outlet).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntityOutlet, outlet.ID, outlet, nil)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete outlet")
+		return
+	}
+
+	utils.OKResponse(c, "Outlet deleted successfully", nil)
+}
+
+// findOutlet loads the outlet referenced by the :id param, writing an error response on failure
+func (h *OutletHandler) findOutlet(c *gin.Context) (*models.Outlet, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid outlet ID")
+		return nil, false
+	}
+
+	var outlet models.Outlet
+	if err := h.DB.WithContext(c).First(&outlet, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Outlet not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch outlet")
+		return nil, false
+	}
+
+	return &outlet, true
+}
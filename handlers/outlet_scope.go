@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"strconv"
+
+	"interview-user/middleware"
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// outletScope describes which outlets' records the caller may see
+type outletScope struct {
+	all      bool
+	outletID *uint
+}
+
+// assignedOutletID returns the outlet the authenticated user is assigned to, if any
+func assignedOutletID(c *gin.Context) *uint {
+	if id, exists := c.Get("outlet_id"); exists {
+		outletID := id.(uint)
+		return &outletID
+	}
+	return nil
+}
+
+// callerOutletScope returns the outlets the caller may access. Users with outlet.all see
+// every outlet; everyone else only sees their assigned outlet, or records without an
+// outlet when they are not assigned to one.
+func callerOutletScope(c *gin.Context, db *gorm.DB) outletScope {
+	if middleware.HasPermission(c, db, models.PermOutletAll) {
+		return outletScope{all: true}
+	}
+	return outletScope{outletID: assignedOutletID(c)}
+}
+
+// listOutletScope is the scope for list endpoints. Users with outlet.all get consolidated
+// data unless they pick a single outlet with the outlet_id query param.
+func listOutletScope(c *gin.Context, db *gorm.DB) (outletScope, bool) {
+	scope := callerOutletScope(c, db)
+
+	param := c.Query("outlet_id")
+	if !scope.all || param == "" {
+		return scope, true
+	}
+
+	id, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid outlet ID")
+		return scope, false
+	}
+	outletID := uint(id)
+	return outletScope{outletID: &outletID}, true
+}
+
+// apply limits query to the scope using the given outlet column
+func (s outletScope) apply(query *gorm.DB, column string) *gorm.DB {
+	if s.all {
+		return query
+	}
+	if s.outletID == nil {
+		return query.Where(column + " IS NULL")
+	}
+	return query.Where(column+" = ?", *s.outletID)
+}
+
+// allows reports whether a record assigned to outletID is within the scope
+func (s outletScope) allows(outletID *uint) bool {
+	if s.all {
+		return true
+	}
+	if s.outletID == nil || outletID == nil {
+		return s.outletID == nil && outletID == nil
+	}
+	return *s.outletID == *outletID
+}
+
+// resolveOutletAssignment returns the outlet a new or updated record should belong to.
+// requested is the outlet_id sent by the client: 0 clears the assignment and nil keeps
+// current. Callers without outlet.all can only assign their own outlet. It writes an
+// error response and returns false when the assignment is not allowed.
+func resolveOutletAssignment(c *gin.Context, db *gorm.DB, requested *uint, current *uint) (*uint, bool) {
+	if requested == nil {
+		return current, true
+	}
+
+	var outletID *uint
+	if *requested != 0 {
+		var outlet models.Outlet
+		if err := db.Where("id = ? AND is_active = ?", *requested, true).First(&outlet).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				utils.BadRequestResponse(c, "Outlet not found")
+				return nil, false
+			}
+			utils.InternalServerErrorResponse(c, "Failed to fetch outlet")
+			return nil, false
+		}
+		outletID = &outlet.ID
+	}
+
+	if !callerOutletScope(c, db).allows(outletID) {
+		utils.ForbiddenResponse(c, "You can only assign your own outlet")
+		return nil, false
+	}
+	return outletID, true
+}
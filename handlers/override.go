@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+
+	"interview-user/middleware"
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Supervisor override headers. The supervisor authenticates with either their password or PIN.
+const (
+	OverrideUsernameHeader = "X-Override-Username"
+	OverridePasswordHeader = "X-Override-Password"
+	OverridePINHeader      = "X-Override-PIN"
+)
+
+// checkCredentialThrottle writes a 429 response and returns false while the username or the
+// terminal is locked out after too many wrong PINs or supervisor credentials
+func checkCredentialThrottle(c *gin.Context, credentials *utils.CredentialThrottle, tenantID uint, username string, terminalID uint) bool {
+	remaining, locked := credentials.Locked(tenantID, username, terminalID)
+	if !locked {
+		return true
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+	utils.TooManyRequestsResponse(c, "Too many failed attempts, try again later")
+	return false
+}
+
+// requireApproval checks that the caller holds permission, or that the request carries the
+// credentials of a supervisor who does. It returns the approving supervisor, or nil when the
+// caller may perform the action on their own, and writes an error response when not approved.
+// Wrong supervisor credentials count towards the same lockout as PIN logins.
+func requireApproval(c *gin.Context, db *gorm.DB, credentials *utils.CredentialThrottle, permission models.Permission) (*models.User, bool) {
+	if middleware.HasPermission(c, db, permission) {
+		return nil, true
+	}
+
+	username := c.GetHeader(OverrideUsernameHeader)
+	password := c.GetHeader(OverridePasswordHeader)
+	pin := c.GetHeader(OverridePINHeader)
+	if username == "" || (password == "" && pin == "") {
+		utils.ForbiddenResponse(c, "Supervisor approval is required for this action")
+		return nil, false
+	}
+
+	tenantID := c.GetUint("tenant_id")
+	terminalID := c.GetUint("terminal_id")
+	if !checkCredentialThrottle(c, credentials, tenantID, username, terminalID) {
+		return nil, false
+	}
+
+	var supervisor models.User
+	if err := db.Where("username = ? AND is_active = ?", username, true).First(&supervisor).Error; err != nil {
+		credentials.Fail(tenantID, username, terminalID)
+		utils.ForbiddenResponse(c, "Invalid supervisor credentials")
+		return nil, false
+	}
+
+	hash, secret := supervisor.Password, password
+	if password == "" {
+		hash, secret = supervisor.PIN, pin
+	}
+	if hash == "" || bcrypt.CompareHashAndPassword([]byte(hash), []byte(secret)) != nil {
+		credentials.Fail(tenantID, username, terminalID)
+		utils.ForbiddenResponse(c, "Invalid supervisor credentials")
+		return nil, false
+	}
+	credentials.Succeed(tenantID, username)
+
+	permissions, err := middleware.RolePermissions(db, supervisor.TenantID, supervisor.Role)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to resolve permissions")
+		return nil, false
+	}
+	if !permissions[permission] {
+		utils.ForbiddenResponse(c, "Supervisor is not allowed to approve this action")
+		return nil, false
+	}
+
+	return &supervisor, true
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"interview-user/models"
+	"interview-user/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// validateNewPassword checks password against the policy and the user's recent passwords.
+// It returns a client-facing message when the password is rejected.
+func validateNewPassword(db *gorm.DB, policy utils.PasswordPolicy, user *models.User, password string) (string, error) {
+	if err := policy.Validate(password); err != nil {
+		return err.Error(), nil
+	}
+
+	if user.ID == 0 || policy.HistorySize <= 0 {
+		return "", nil
+	}
+
+	// The current password counts as one of the recent passwords
+	recent := []string{user.Password}
+	if policy.HistorySize > 1 {
+		var history []models.PasswordHistory
+		if err := db.Where("user_id = ?", user.ID).
+			Order("created_at DESC").
+			Limit(policy.HistorySize - 1).
+			Find(&history).Error; err != nil {
+			return "", err
+		}
+		for _, h := range history {
+			recent = append(recent, h.PasswordHash)
+		}
+	}
+
+	for _, hash := range recent {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return "Password was used recently, choose a different one", nil
+		}
+	}
+
+	return "", nil
+}
+
+// setUserPassword hashes password onto user and keeps the previous hash in the history table.
+// The caller is responsible for saving user.
+func setUserPassword(db *gorm.DB, user *models.User, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if user.ID != 0 && user.Password != "" {
+		if err := db.Create(&models.PasswordHistory{
+			UserID:       user.ID,
+			PasswordHash: user.Password,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	user.Password = string(hashedPassword)
+	return nil
+}
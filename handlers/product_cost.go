@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"interview-user/middleware"
+	"interview-user/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// productWithCost is a product serialized together with its cost price
+type productWithCost struct {
+	models.Product
+	CostPrice float64 `json:"cost_price"`
+}
+
+func withCost(product models.Product) productWithCost {
+	return productWithCost{Product: product, CostPrice: product.CostPrice}
+}
+
+// productView returns product as it is sent to the caller: with its cost price for
+// callers holding product.manage, without it for everyone else
+func productView(c *gin.Context, db *gorm.DB, product models.Product) interface{} {
+	if middleware.HasPermission(c, db, models.PermProductManage) {
+		return withCost(product)
+	}
+	return product
+}
+
+// productViews is productView for a list of products
+func productViews(c *gin.Context, db *gorm.DB, products []models.Product) interface{} {
+	if !middleware.HasPermission(c, db, models.PermProductManage) {
+		return products
+	}
+	views := make([]productWithCost, 0, len(products))
+	for _, product := range products {
+		views = append(views, withCost(product))
+	}
+	return views
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ProductHandler struct {
+	DB       *gorm.DB
+	Barcodes utils.BarcodeFormat
+}
+
+func NewProductHandler(db *gorm.DB, barcodes utils.BarcodeFormat) *ProductHandler {
+	return &ProductHandler{
+		DB:       db,
+		Barcodes: barcodes,
+	}
+}
+
+type CreateProductRequest struct {
+	SKU          string  `json:"sku" binding:"required,max=50"`
+	Barcode      string  `json:"barcode" binding:"max=50"`
+	Name         string  `json:"name" binding:"required,max=255"`
+	CategoryID   *uint   `json:"category_id"`
+	Price        float64 `json:"price" binding:"min=0"`
+	CostPrice    float64 `json:"cost_price" binding:"min=0"`
+	SoldByWeight bool    `json:"sold_by_weight"`
+}
+
+// UpdateProductRequest changes product details; "category_id": 0 removes the category
+// and "barcode": "" removes the barcode.
+// Stock and cost are not editable here: they follow from goods receipts and other stock movements.
+type UpdateProductRequest struct {
+	SKU          *string  `json:"sku" binding:"omitempty,min=1,max=50"`
+	Barcode      *string  `json:"barcode" binding:"omitempty,max=50"`
+	Name         *string  `json:"name" binding:"omitempty,min=1,max=255"`
+	CategoryID   *uint    `json:"category_id"`
+	Price        *float64 `json:"price" binding:"omitempty,min=0"`
+	IsActive     *bool    `json:"is_active"`
+	SoldByWeight *bool    `json:"sold_by_weight"`
+}
+
+// GetAll returns products with pagination, optionally filtered by ?search= on SKU or name
+// and by ?category_id=, which includes the products of its subcategories
+func (h *ProductHandler) GetAll(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	query := h.DB.WithContext(c).Model(&models.Product{})
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		pattern := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(sku) LIKE ? OR LOWER(name) LIKE ?", pattern, pattern)
+	}
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		id, err := strconv.ParseUint(categoryID, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid category_id")
+			return
+		}
+		categoryIDs, err := categorySubtreeIDs(h.DB.WithContext(c), uint(id))
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to fetch categories")
+			return
+		}
+		query = query.Where("category_id IN ?", categoryIDs)
+	}
+
+	var total int64
+	var products []models.Product
+
+	if err := query.Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count products")
+		return
+	}
+
+	if err := query.Preload("Category").Preload("Variants").Order("name ASC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&products).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch products")
+		return
+	}
+
+	utils.OKResponse(c, "Products retrieved successfully", utils.PaginatedResponse{
+		Items:      productViews(c, h.DB.WithContext(c), products),
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// GetByID returns a product by ID
+func (h *ProductHandler) GetByID(c *gin.Context) {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return
+	}
+
+	utils.OKResponse(c, "Product retrieved successfully", productView(c, h.DB.WithContext(c), *product))
+}
+
+// Create creates a new product with no stock
+func (h *ProductHandler) Create(c *gin.Context) {
+	var req CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if h.skuExists(c, req.SKU, 0) {
+		utils.BadRequestResponse(c, "SKU already exists")
+		return
+	}
+
+	barcode, ok := h.resolveBarcode(c, req.Barcode, 0, 0)
+	if !ok {
+		return
+	}
+
+	categoryID, ok := h.resolveCategory(c, req.CategoryID)
+	if !ok {
+		return
+	}
+
+	product := models.Product{
+		SKU:          req.SKU,
+		Barcode:      barcode,
+		Name:         req.Name,
+		CategoryID:   categoryID,
+		Price:        req.Price,
+		CostPrice:    req.CostPrice,
+		SoldByWeight: req.SoldByWeight,
+		IsActive:     true,
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityProduct, product.ID, nil, withCost(product))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create product")
+		return
+	}
+
+	utils.CreatedResponse(c, "Product created successfully", withCost(product))
+}
+
+// Update partially updates a product
+func (h *ProductHandler) Update(c *gin.Context) {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := *product
+
+	if req.SKU != nil && *req.SKU != product.SKU {
+		if h.skuExists(c, *req.SKU, product.ID) {
+			utils.BadRequestResponse(c, "SKU already exists")
+			return
+		}
+		product.SKU = *req.SKU
+	}
+	if req.Barcode != nil {
+		barcode, ok := h.resolveBarcode(c, *req.Barcode, product.ID, 0)
+		if !ok {
+			return
+		}
+		product.Barcode = barcode
+	}
+	if req.Name != nil {
+		product.Name = *req.Name
+	}
+	if req.CategoryID != nil {
+		categoryID, ok := h.resolveCategory(c, req.CategoryID)
+		if !ok {
+			return
+		}
+		product.CategoryID = categoryID
+		product.Category = nil
+	}
+	if req.Price != nil {
+		product.Price = *req.Price
+	}
+	if req.IsActive != nil {
+		product.IsActive = *req.IsActive
+	}
+	if req.SoldByWeight != nil {
+		product.SoldByWeight = *req.SoldByWeight
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(product).Select("SKU", "Barcode", "Name", "CategoryID", "Price", "IsActive", "SoldByWeight").Updates(product).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityProduct, product.ID, withCost(before), withCost(*product))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update product")
+		return
+	}
+
+	utils.OKResponse(c, "Product updated successfully", withCost(*product))
+}
+
+// Delete soft deletes a product
+func (h *ProductHandler) Delete(c *gin.Context) {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(product).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntityProduct, product.ID, withCost(*product), nil)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete product")
+		return
+	}
+
+	utils.OKResponse(c, "Product deleted successfully", nil)
+}
+
+// GetStockMovements returns the stock ledger of a product, newest first
+func (h *ProductHandler) GetStockMovements(c *gin.Context) {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return
+	}
+
+	pagination := utils.GetPagination(c)
+	query := h.DB.WithContext(c).Model(&models.StockMovement{}).Where("product_id = ?", product.ID)
+
+	var total int64
+	var movements []models.StockMovement
+
+	if err := query.Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count stock movements")
+		return
+	}
+
+	if err := query.Order("id DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&movements).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch stock movements")
+		return
+	}
+
+	utils.OKResponse(c, "Stock movements retrieved successfully", utils.PaginatedResponse{
+		Items:      movements,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// resolveCategory checks the requested category exists; 0 means no category.
+// It writes an error response and returns false when the category is unknown.
+func (h *ProductHandler) resolveCategory(c *gin.Context, requested *uint) (*uint, bool) {
+	if requested == nil || *requested == 0 {
+		return nil, true
+	}
+
+	var category models.Category
+	if err := h.DB.WithContext(c).First(&category, *requested).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.BadRequestResponse(c, "Category not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch category")
+		return nil, false
+	}
+	return &category.ID, true
+}
+
+// skuExists reports whether another product, including deleted ones, uses sku
+func (h *ProductHandler) skuExists(c *gin.Context, sku string, exceptID uint) bool {
+	var count int64
+	h.DB.WithContext(c).Unscoped().Model(&models.Product{}).Where("sku = ? AND id <> ?", sku, exceptID).Count(&count)
+	return count > 0 || h.variantSKUExists(c, sku, 0)
+}
+
+// findProduct loads the product referenced by the :id param, writing an error response on failure
+func (h *ProductHandler) findProduct(c *gin.Context) (*models.Product, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid product ID")
+		return nil, false
+	}
+
+	var product models.Product
+	if err := h.DB.WithContext(c).Preload("Category").Preload("Variants").Preload("ModifierGroups.Modifiers").First(&product, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Product not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch product")
+		return nil, false
+	}
+
+	return &product, true
+}
+
+// resolveBarcode normalizes a product or variant barcode and checks that no other product
+// or variant, including deleted ones, uses it; an empty code means no barcode. It writes
+// an error response and returns false when the barcode is invalid or taken.
+func (h *ProductHandler) resolveBarcode(c *gin.Context, code string, exceptProductID, exceptVariantID uint) (*string, bool) {
+	if strings.TrimSpace(code) == "" {
+		return nil, true
+	}
+
+	barcode, _, err := utils.NormalizeBarcode(code)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid barcode check digit")
+		return nil, false
+	}
+
+	var products, variants int64
+	h.DB.WithContext(c).Unscoped().Model(&models.Product{}).Where("barcode = ? AND id <> ?", barcode, exceptProductID).Count(&products)
+	h.DB.WithContext(c).Unscoped().Model(&models.ProductVariant{}).Where("barcode = ? AND id <> ?", barcode, exceptVariantID).Count(&variants)
+	if products > 0 || variants > 0 {
+		utils.BadRequestResponse(c, "Barcode already exists")
+		return nil, false
+	}
+
+	return &barcode, true
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Kinds of in-store barcodes reported by the product lookup, next to the symbologies
+// of utils.NormalizeBarcode
+const (
+	BarcodeVariableWeight = "variable_weight"
+	BarcodeVariablePrice  = "variable_price"
+)
+
+// ProductLookupResponse is the product behind a scanned code. Item is ready to be sent
+// as a sale order item; clients only add modifier_ids, and variant_id when the code
+// matched a product that has variants.
+type ProductLookupResponse struct {
+	Code        string                     `json:"code"`
+	Type        string                     `json:"type"`
+	Product     models.Product             `json:"product"`
+	Variant     *models.ProductVariant     `json:"variant"`
+	WeightGrams *int                       `json:"weight_grams,omitempty"`
+	Item        CreateSaleOrderItemRequest `json:"item"`
+}
+
+// Lookup finds the active product or variant for a scanned ?code=. EAN-13 and UPC-A codes
+// must have a valid check digit. Codes are matched against barcodes, then internal codes
+// also against SKUs, and finally in-store weight or price barcodes are matched by their
+// item code, with the weight or price taken from the code.
+func (h *ProductHandler) Lookup(c *gin.Context) {
+	code, symbology, err := utils.NormalizeBarcode(c.Query("code"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid barcode check digit")
+		return
+	}
+	if code == "" {
+		utils.BadRequestResponse(c, "code is required")
+		return
+	}
+
+	db := h.DB.WithContext(c)
+	response := ProductLookupResponse{
+		Code: code,
+		Type: symbology,
+		Item: CreateSaleOrderItemRequest{Quantity: 1},
+	}
+
+	product, variant, err := findProductByCode(db, "barcode", code)
+	if err == nil && product == nil && symbology == utils.BarcodeInternal {
+		product, variant, err = findProductByCode(db, "sku", code)
+	}
+
+	var measure utils.VariableMeasure
+	if err == nil && product == nil {
+		var ok bool
+		if measure, ok = h.Barcodes.ParseVariableMeasure(code); ok {
+			// A scale or label printer fault must not ring up a free item
+			if measure.Value == 0 {
+				if measure.Weight {
+					utils.BadRequestResponse(c, "Barcode weight must be greater than zero")
+				} else {
+					utils.BadRequestResponse(c, "Barcode price must be greater than zero")
+				}
+				return
+			}
+			product, variant, err = findProductByCode(db, "barcode", measure.ItemCode)
+		}
+	}
+
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch product")
+		return
+	}
+	if product == nil {
+		utils.NotFoundResponse(c, "Product not found for this code")
+		return
+	}
+
+	response.Product = *product
+	response.Variant = variant
+	response.Item.ProductID = &product.ID
+
+	if variant != nil {
+		response.Item.VariantID = &variant.ID
+	}
+
+	if measure.ItemCode != "" {
+		if measure.Weight && !product.SoldByWeight {
+			// Selling a weighed item as one unit would take a whole unit off the stock
+			utils.BadRequestResponse(c, "Product is not sold by weight")
+			return
+		}
+		if measure.Weight {
+			// Price is per kilogram; the sale order prices the weighed item from the
+			// catalog, so only the weight is passed on
+			response.Type = BarcodeVariableWeight
+			response.WeightGrams = &measure.Value
+			response.Item.WeightGrams = &measure.Value
+		} else {
+			response.Type = BarcodeVariablePrice
+			unitPrice := float64(measure.Value)
+			response.Item.UnitPrice = &unitPrice
+		}
+	}
+
+	utils.OKResponse(c, "Product found", response)
+}
+
+// findProductByCode finds the active product, and variant when the code belongs to one,
+// whose column (barcode or sku) equals code. It returns a nil product when nothing matches.
+func findProductByCode(db *gorm.DB, column, code string) (*models.Product, *models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	if err := db.Where(column+" = ? AND is_active = ?", code, true).Limit(1).Find(&variants).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var productID uint
+	if len(variants) > 0 {
+		productID = variants[0].ProductID
+	} else {
+		var products []models.Product
+		if err := db.Where(column+" = ? AND is_active = ?", code, true).Limit(1).Find(&products).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(products) == 0 {
+			return nil, nil, nil
+		}
+		productID = products[0].ID
+	}
+
+	products, err := loadSaleProducts(db, []uint{productID})
+	if err != nil {
+		return nil, nil, err
+	}
+	product, found := products[productID]
+	if !found {
+		return nil, nil, nil
+	}
+
+	if len(variants) > 0 {
+		variant, found := findVariant(product.Variants, variants[0].ID)
+		if !found {
+			return nil, nil, nil
+		}
+		return &product, &variant, nil
+	}
+	return &product, nil, nil
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateProductVariantRequest struct {
+	SKU     string  `json:"sku" binding:"required,max=50"`
+	Barcode string  `json:"barcode" binding:"max=50"`
+	Name    string  `json:"name" binding:"required,max=255"`
+	Price   float64 `json:"price" binding:"min=0"`
+}
+
+type UpdateProductVariantRequest struct {
+	SKU      *string  `json:"sku" binding:"omitempty,min=1,max=50"`
+	Barcode  *string  `json:"barcode" binding:"omitempty,max=50"`
+	Name     *string  `json:"name" binding:"omitempty,min=1,max=255"`
+	Price    *float64 `json:"price" binding:"omitempty,min=0"`
+	IsActive *bool    `json:"is_active"`
+}
+
+type SetProductModifierGroupsRequest struct {
+	ModifierGroupIDs []uint `json:"modifier_group_ids" binding:"required"`
+}
+
+// CreateVariant adds a variant to a product
+func (h *ProductHandler) CreateVariant(c *gin.Context) {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return
+	}
+
+	var req CreateProductVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if h.skuExists(c, req.SKU, 0) {
+		utils.BadRequestResponse(c, "SKU already exists")
+		return
+	}
+
+	barcode, ok := h.resolveBarcode(c, req.Barcode, 0, 0)
+	if !ok {
+		return
+	}
+
+	variant := models.ProductVariant{
+		ProductID: product.ID,
+		SKU:       req.SKU,
+		Barcode:   barcode,
+		Name:      req.Name,
+		Price:     req.Price,
+		IsActive:  true,
+	}
+
+	before := productVariantsAuditState(product)
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&variant).Error; err != nil {
+			return err
+		}
+		product.Variants = append(product.Variants, variant)
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityProduct, product.ID, before, productVariantsAuditState(product))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create variant")
+		return
+	}
+
+	utils.CreatedResponse(c, "Variant created successfully", variant)
+}
+
+// UpdateVariant partially updates a product variant
+func (h *ProductHandler) UpdateVariant(c *gin.Context) {
+	product, variant, ok := h.findVariant(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateProductVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := productVariantsAuditState(product)
+
+	if req.SKU != nil && *req.SKU != variant.SKU {
+		if h.variantSKUExists(c, *req.SKU, variant.ID) || h.productSKUExists(c, *req.SKU) {
+			utils.BadRequestResponse(c, "SKU already exists")
+			return
+		}
+		variant.SKU = *req.SKU
+	}
+	if req.Barcode != nil {
+		barcode, ok := h.resolveBarcode(c, *req.Barcode, 0, variant.ID)
+		if !ok {
+			return
+		}
+		variant.Barcode = barcode
+	}
+	if req.Name != nil {
+		variant.Name = *req.Name
+	}
+	if req.Price != nil {
+		variant.Price = *req.Price
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(variant).Select("SKU", "Barcode", "Name", "Price", "IsActive").Updates(variant).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityProduct, product.ID, before, productVariantsAuditState(product))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update variant")
+		return
+	}
+
+	utils.OKResponse(c, "Variant updated successfully", variant)
+}
+
+// DeleteVariant soft deletes a product variant. Past orders keep the variant name.
+func (h *ProductHandler) DeleteVariant(c *gin.Context) {
+	product, variant, ok := h.findVariant(c)
+	if !ok {
+		return
+	}
+
+	before := productVariantsAuditState(product)
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(variant).Error; err != nil {
+			return err
+		}
+		for i := range product.Variants {
+			if product.Variants[i].ID == variant.ID {
+				product.Variants = append(product.Variants[:i], product.Variants[i+1:]...)
+				break
+			}
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityProduct, product.ID, before, productVariantsAuditState(product))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete variant")
+		return
+	}
+
+	utils.OKResponse(c, "Variant deleted successfully", nil)
+}
+
+// SetModifierGroups replaces the modifier groups offered for a product
+func (h *ProductHandler) SetModifierGroups(c *gin.Context) {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return
+	}
+
+	var req SetProductModifierGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var groups []models.ModifierGroup
+	if len(req.ModifierGroupIDs) > 0 {
+		if err := h.DB.WithContext(c).Where("id IN ?", req.ModifierGroupIDs).Find(&groups).Error; err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to fetch modifier groups")
+			return
+		}
+	}
+	if len(groups) != len(uniqueIDs(req.ModifierGroupIDs)) {
+		utils.BadRequestResponse(c, "Modifier group not found")
+		return
+	}
+
+	before := productModifierGroupsAuditState(product)
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(product).Association("ModifierGroups").Replace(groups); err != nil {
+			return err
+		}
+		// Touch the product so offline terminals pick up the new groups in /sync/changes
+		if err := tx.Model(product).Update("updated_at", time.Now()).Error; err != nil {
+			return err
+		}
+		product.ModifierGroups = groups
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityProduct, product.ID, before, productModifierGroupsAuditState(product))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update modifier groups")
+		return
+	}
+
+	h.DB.WithContext(c).Preload("Category").Preload("Variants").Preload("ModifierGroups.Modifiers").First(product, product.ID)
+
+	utils.OKResponse(c, "Modifier groups updated successfully", withCost(*product))
+}
+
+// productVariantsAuditState is the audited list of a product's variants
+func productVariantsAuditState(product *models.Product) map[string]interface{} {
+	variants := make([]map[string]interface{}, 0, len(product.Variants))
+	for _, variant := range product.Variants {
+		variants = append(variants, map[string]interface{}{
+			"sku":       variant.SKU,
+			"barcode":   variant.Barcode,
+			"name":      variant.Name,
+			"price":     variant.Price,
+			"is_active": variant.IsActive,
+		})
+	}
+	return map[string]interface{}{"variants": variants}
+}
+
+// productModifierGroupsAuditState is the audited list of a product's modifier groups
+func productModifierGroupsAuditState(product *models.Product) map[string]interface{} {
+	ids := make([]uint, 0, len(product.ModifierGroups))
+	for _, group := range product.ModifierGroups {
+		ids = append(ids, group.ID)
+	}
+	return map[string]interface{}{"modifier_group_ids": ids}
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	var unique []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// productSKUExists reports whether a product, including deleted ones, uses sku
+func (h *ProductHandler) productSKUExists(c *gin.Context, sku string) bool {
+	var count int64
+	h.DB.WithContext(c).Unscoped().Model(&models.Product{}).Where("sku = ?", sku).Count(&count)
+	return count > 0
+}
+
+// variantSKUExists reports whether another variant, including deleted ones, uses sku
+func (h *ProductHandler) variantSKUExists(c *gin.Context, sku string, exceptID uint) bool {
+	var count int64
+	h.DB.WithContext(c).Unscoped().Model(&models.ProductVariant{}).Where("sku = ? AND id <> ?", sku, exceptID).Count(&count)
+	return count > 0
+}
+
+// findVariant loads the product and the variant referenced by the :id and :variantId params,
+// writing an error response on failure
+func (h *ProductHandler) findVariant(c *gin.Context) (*models.Product, *models.ProductVariant, bool) {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	variantID, err := strconv.ParseUint(c.Param("variantId"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid variant ID")
+		return nil, nil, false
+	}
+
+	for i := range product.Variants {
+		if product.Variants[i].ID == uint(variantID) {
+			return product, &product.Variants[i], true
+		}
+	}
+
+	utils.NotFoundResponse(c, "Variant not found")
+	return nil, nil, false
+}
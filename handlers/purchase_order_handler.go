@@ -0,0 +1,547 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PurchaseOrderHandler struct {
+	DB *gorm.DB
+}
+
+func NewPurchaseOrderHandler(db *gorm.DB) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{DB: db}
+}
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID uint                             `json:"supplier_id" binding:"required"`
+	Notes      string                           `json:"notes"`
+	Items      []CreatePurchaseOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type CreatePurchaseOrderItemRequest struct {
+	ProductID uint    `json:"product_id" binding:"required"`
+	Quantity  int     `json:"quantity" binding:"required,min=1"`
+	UnitCost  float64 `json:"unit_cost" binding:"min=0"`
+}
+
+// UpdatePurchaseOrderRequest follows PATCH semantics; items, when given, replace all items.
+// Only orders that have not received anything yet can be changed.
+type UpdatePurchaseOrderRequest struct {
+	SupplierID *uint                            `json:"supplier_id"`
+	Notes      *string                          `json:"notes"`
+	Items      []CreatePurchaseOrderItemRequest `json:"items" binding:"omitempty,min=1,dive"`
+}
+
+type CreateGoodsReceiptRequest struct {
+	Notes string                          `json:"notes"`
+	Items []CreateGoodsReceiptItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// CreateGoodsReceiptItemRequest receives quantity units of a purchase order item.
+// UnitCost defaults to the cost on the purchase order when omitted.
+type CreateGoodsReceiptItemRequest struct {
+	PurchaseOrderItemID uint     `json:"purchase_order_item_id" binding:"required"`
+	Quantity            int      `json:"quantity" binding:"required,min=1"`
+	UnitCost            *float64 `json:"unit_cost" binding:"omitempty,min=0"`
+}
+
+// purchaseOrderError is a client error detected inside a purchase order transaction
+type purchaseOrderError struct {
+	message string
+}
+
+func (e *purchaseOrderError) Error() string {
+	return e.message
+}
+
+// purchaseOrderAuditState is the audited snapshot of a purchase order and its items
+func purchaseOrderAuditState(order *models.PurchaseOrder) map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, map[string]interface{}{
+			"product_id":        item.ProductID,
+			"quantity_ordered":  item.QuantityOrdered,
+			"quantity_received": item.QuantityReceived,
+			"unit_cost":         item.UnitCost,
+		})
+	}
+	return map[string]interface{}{
+		"po_number":    order.PONumber,
+		"supplier_id":  order.SupplierID,
+		"status":       order.Status,
+		"total_amount": order.TotalAmount,
+		"notes":        order.Notes,
+		"items":        items,
+	}
+}
+
+// GetAll returns purchase orders with pagination, optionally filtered by ?status= and ?supplier_id=
+func (h *PurchaseOrderHandler) GetAll(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	query := h.DB.WithContext(c).Model(&models.PurchaseOrder{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if supplierID := c.Query("supplier_id"); supplierID != "" {
+		id, err := strconv.ParseUint(supplierID, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid supplier_id")
+			return
+		}
+		query = query.Where("supplier_id = ?", id)
+	}
+
+	var total int64
+	var orders []models.PurchaseOrder
+
+	if err := query.Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count purchase orders")
+		return
+	}
+
+	if err := query.Preload("Supplier").Preload("Items").
+		Order("created_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&orders).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch purchase orders")
+		return
+	}
+
+	utils.OKResponse(c, "Purchase orders retrieved successfully", utils.PaginatedResponse{
+		Items:      orders,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// GetByID returns a purchase order with its supplier and items
+func (h *PurchaseOrderHandler) GetByID(c *gin.Context) {
+	order, ok := h.findPurchaseOrder(c)
+	if !ok {
+		return
+	}
+
+	utils.SetETag(c, order.Version)
+	utils.OKResponse(c, "Purchase order retrieved successfully", order)
+}
+
+// Create places a new purchase order with a supplier
+func (h *PurchaseOrderHandler) Create(c *gin.Context) {
+	var req CreatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if !h.checkSupplier(c, req.SupplierID) {
+		return
+	}
+
+	items, totalAmount, ok := h.buildPurchaseOrderItems(c, req.Items)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	order := models.PurchaseOrder{
+		SupplierID:  req.SupplierID,
+		Status:      models.PurchaseOrderOrdered,
+		TotalAmount: totalAmount,
+		Notes:       req.Notes,
+		CreatedByID: userID.(uint),
+		Items:       items,
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		poNumber, err := nextSequenceNumber(tx, purchaseOrderNumbers, purchaseOrderSequenceScope, "", time.Now())
+		if err != nil {
+			return err
+		}
+		order.PONumber = poNumber
+
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityPurchase, order.ID, nil, purchaseOrderAuditState(&order))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create purchase order")
+		return
+	}
+
+	h.DB.WithContext(c).Preload("Supplier").Preload("Items.Product").First(&order, order.ID)
+
+	utils.SetETag(c, order.Version)
+	utils.CreatedResponse(c, "Purchase order created successfully", order)
+}
+
+// Update partially updates a purchase order that has not received any goods yet
+func (h *PurchaseOrderHandler) Update(c *gin.Context) {
+	order, ok := h.findPurchaseOrder(c)
+	if !ok {
+		return
+	}
+
+	if !utils.CheckIfMatch(c, order.Version) {
+		return
+	}
+
+	if order.Status != models.PurchaseOrderOrdered {
+		utils.BadRequestResponse(c, "Purchase order can no longer be changed once goods were received")
+		return
+	}
+
+	var req UpdatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := purchaseOrderAuditState(order)
+
+	if req.SupplierID != nil && *req.SupplierID != order.SupplierID {
+		if !h.checkSupplier(c, *req.SupplierID) {
+			return
+		}
+		order.SupplierID = *req.SupplierID
+		order.Supplier = nil
+	}
+	if req.Notes != nil {
+		order.Notes = *req.Notes
+	}
+
+	var items []models.PurchaseOrderItem
+	if req.Items != nil {
+		var totalAmount float64
+		items, totalAmount, ok = h.buildPurchaseOrderItems(c, req.Items)
+		if !ok {
+			return
+		}
+		order.TotalAmount = totalAmount
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// Lock the order so a concurrent goods receipt cannot slip in between
+		var current models.PurchaseOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, order.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != models.PurchaseOrderOrdered {
+			return &purchaseOrderError{message: "Purchase order can no longer be changed once goods were received"}
+		}
+
+		if items != nil {
+			if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&models.PurchaseOrderItem{}).Error; err != nil {
+				return err
+			}
+			for i := range items {
+				items[i].PurchaseOrderID = order.ID
+			}
+			if err := tx.Create(&items).Error; err != nil {
+				return err
+			}
+			order.Items = items
+		}
+
+		if err := updateVersioned(tx, order, &order.Version); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityPurchase, order.ID, before, purchaseOrderAuditState(order))
+	})
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "Purchase order was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
+		var poErr *purchaseOrderError
+		if errors.As(err, &poErr) {
+			utils.BadRequestResponse(c, poErr.message)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update purchase order")
+		return
+	}
+
+	h.DB.WithContext(c).Preload("Supplier").Preload("Items.Product").First(order, order.ID)
+
+	utils.SetETag(c, order.Version)
+	utils.OKResponse(c, "Purchase order updated successfully", order)
+}
+
+// Delete cancels a purchase order that has not received any goods yet
+func (h *PurchaseOrderHandler) Delete(c *gin.Context) {
+	order, ok := h.findPurchaseOrder(c)
+	if !ok {
+		return
+	}
+
+	if !utils.CheckIfMatch(c, order.Version) {
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// Lock the order so a concurrent update or goods receipt cannot slip in between
+		var current models.PurchaseOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, order.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != models.PurchaseOrderOrdered {
+			return &purchaseOrderError{message: "Purchase order can no longer be cancelled once goods were received"}
+		}
+		if current.Version != order.Version {
+			return errVersionConflict
+		}
+
+		if err := tx.Delete(order).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntityPurchase, order.ID, purchaseOrderAuditState(order), nil)
+	})
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "Purchase order was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
+		var poErr *purchaseOrderError
+		if errors.As(err, &poErr) {
+			utils.BadRequestResponse(c, poErr.message)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to delete purchase order")
+		return
+	}
+
+	utils.OKResponse(c, "Purchase order deleted successfully", nil)
+}
+
+// Receive records a goods receipt against a purchase order. Received quantities are added
+// to product stock at the receipt cost, and the order becomes partially_received or,
+// once every item is complete, received.
+func (h *PurchaseOrderHandler) Receive(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid purchase order ID")
+		return
+	}
+
+	var req CreateGoodsReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	receipt := models.GoodsReceipt{
+		Notes:        req.Notes,
+		ReceivedByID: userID.(uint),
+	}
+
+	err = h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// Receipts for the same order are serialized by the row lock
+		var order models.PurchaseOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&order, id).Error; err != nil {
+			return err
+		}
+		if order.Status == models.PurchaseOrderReceived {
+			return &purchaseOrderError{message: "Purchase order has already been fully received"}
+		}
+
+		before := purchaseOrderAuditState(&order)
+		receipt.PurchaseOrderID = order.ID
+
+		itemIndex := make(map[uint]int, len(order.Items))
+		for i, item := range order.Items {
+			itemIndex[item.ID] = i
+		}
+
+		for _, reqItem := range req.Items {
+			i, found := itemIndex[reqItem.PurchaseOrderItemID]
+			if !found {
+				return &purchaseOrderError{message: "Item does not belong to this purchase order"}
+			}
+			item := &order.Items[i]
+			if item.QuantityReceived+reqItem.Quantity > item.QuantityOrdered {
+				return &purchaseOrderError{message: "Received quantity exceeds the quantity still outstanding"}
+			}
+
+			unitCost := item.UnitCost
+			if reqItem.UnitCost != nil {
+				unitCost = *reqItem.UnitCost
+			}
+			item.QuantityReceived += reqItem.Quantity
+			receipt.Items = append(receipt.Items, models.GoodsReceiptItem{
+				PurchaseOrderItemID: item.ID,
+				ProductID:           item.ProductID,
+				Quantity:            reqItem.Quantity,
+				UnitCost:            unitCost,
+			})
+		}
+
+		if err := tx.Create(&receipt).Error; err != nil {
+			return err
+		}
+
+		for _, received := range receipt.Items {
+			if err := tx.Model(&models.PurchaseOrderItem{}).Where("id = ?", received.PurchaseOrderItemID).
+				Update("quantity_received", gorm.Expr("quantity_received + ?", received.Quantity)).Error; err != nil {
+				return err
+			}
+			err := applyStockMovement(tx, &models.StockMovement{
+				ProductID:     received.ProductID,
+				Type:          models.StockMovementPurchaseReceipt,
+				Quantity:      received.Quantity,
+				UnitCost:      received.UnitCost,
+				ReferenceType: "goods_receipt",
+				ReferenceID:   receipt.ID,
+				CreatedByID:   receipt.ReceivedByID,
+			})
+			if err == gorm.ErrRecordNotFound {
+				// Only a purged product is missing, soft-deleted ones still take stock
+				return &purchaseOrderError{message: fmt.Sprintf("Product %d of the purchase order no longer exists", received.ProductID)}
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		order.Status = models.PurchaseOrderReceived
+		for _, item := range order.Items {
+			if item.QuantityReceived < item.QuantityOrdered {
+				order.Status = models.PurchaseOrderPartiallyReceived
+				break
+			}
+		}
+		// A receipt changes the order, so ETags taken before it no longer match
+		if err := tx.Model(&order).Updates(map[string]interface{}{
+			"status":  order.Status,
+			"version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		order.Version++
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityPurchase, order.ID, before, purchaseOrderAuditState(&order))
+	})
+	if err != nil {
+		var poErr *purchaseOrderError
+		if errors.As(err, &poErr) {
+			utils.BadRequestResponse(c, poErr.message)
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Purchase order not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to receive goods")
+		return
+	}
+
+	utils.CreatedResponse(c, "Goods received successfully", receipt)
+}
+
+// GetReceipts returns the goods receipts of a purchase order
+func (h *PurchaseOrderHandler) GetReceipts(c *gin.Context) {
+	order, ok := h.findPurchaseOrder(c)
+	if !ok {
+		return
+	}
+
+	var receipts []models.GoodsReceipt
+	if err := h.DB.WithContext(c).Preload("ReceivedBy").Preload("Items").
+		Where("purchase_order_id = ?", order.ID).
+		Order("created_at ASC").
+		Find(&receipts).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch goods receipts")
+		return
+	}
+
+	utils.OKResponse(c, "Goods receipts retrieved successfully", receipts)
+}
+
+// checkSupplier verifies that supplierID is an active supplier, writing an error response otherwise
+func (h *PurchaseOrderHandler) checkSupplier(c *gin.Context, supplierID uint) bool {
+	var supplier models.Supplier
+	if err := h.DB.WithContext(c).Where("id = ? AND is_active = ?", supplierID, true).First(&supplier).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.BadRequestResponse(c, "Supplier not found or inactive")
+			return false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch supplier")
+		return false
+	}
+	return true
+}
+
+// buildPurchaseOrderItems converts requested items into purchase order items and returns their
+// total. Every product must exist and appear only once, so receipts map to a single line.
+func (h *PurchaseOrderHandler) buildPurchaseOrderItems(c *gin.Context, reqItems []CreatePurchaseOrderItemRequest) ([]models.PurchaseOrderItem, float64, bool) {
+	seen := make(map[uint]bool, len(reqItems))
+	productIDs := make([]uint, 0, len(reqItems))
+	for _, item := range reqItems {
+		if seen[item.ProductID] {
+			utils.BadRequestResponse(c, "Each product can only appear once in a purchase order")
+			return nil, 0, false
+		}
+		seen[item.ProductID] = true
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	var count int64
+	if err := h.DB.WithContext(c).Model(&models.Product{}).Where("id IN ?", productIDs).Count(&count).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch products")
+		return nil, 0, false
+	}
+	if int(count) != len(productIDs) {
+		utils.BadRequestResponse(c, "Product not found")
+		return nil, 0, false
+	}
+
+	var totalAmount float64
+	items := make([]models.PurchaseOrderItem, 0, len(reqItems))
+	for _, item := range reqItems {
+		subtotal := float64(item.Quantity) * item.UnitCost
+		totalAmount += subtotal
+		items = append(items, models.PurchaseOrderItem{
+			ProductID:       item.ProductID,
+			QuantityOrdered: item.Quantity,
+			UnitCost:        item.UnitCost,
+			Subtotal:        subtotal,
+		})
+	}
+	return items, totalAmount, true
+}
+
+// findPurchaseOrder loads the purchase order referenced by the :id param, writing an error response on failure
+func (h *PurchaseOrderHandler) findPurchaseOrder(c *gin.Context) (*models.PurchaseOrder, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid purchase order ID")
+		return nil, false
+	}
+
+	var order models.PurchaseOrder
+	if err := h.DB.WithContext(c).Preload("Supplier").Preload("CreatedBy").Preload("Items.Product").First(&order, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Purchase order not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch purchase order")
+		return nil, false
+	}
+
+	return &order, true
+}
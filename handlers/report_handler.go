@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"strconv"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReportHandler struct {
+	DB *gorm.DB
+}
+
+func NewReportHandler(db *gorm.DB) *ReportHandler {
+	return &ReportHandler{DB: db}
+}
+
+// MarginReportRow is the sales, cost and gross margin of one group. Discount is what the
+// items would have sold for at their catalog price minus what they sold for.
+type MarginReportRow struct {
+	ID            *uint   `json:"id"`
+	Label         string  `json:"label"`
+	Quantity      int64   `json:"quantity"`
+	Revenue       float64 `json:"revenue"`
+	Discount      float64 `json:"discount"`
+	Cost          float64 `json:"cost"`
+	GrossMargin   float64 `json:"gross_margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+type MarginReportResponse struct {
+	GroupBy string            `json:"group_by"`
+	Rows    []MarginReportRow `json:"rows"`
+	Total   MarginReportRow   `json:"total"`
+}
+
+// marginGrouping describes how margin rows are keyed and labelled for a group_by value
+type marginGrouping struct {
+	joins   []string
+	id      string
+	label   string
+	groupBy string
+	orderBy string
+}
+
+var marginGroupings = map[string]marginGrouping{
+	"product": {
+		id:      "sale_order_items.product_id",
+		label:   "CASE WHEN sale_order_items.product_id IS NULL THEN 'Other items' ELSE MAX(sale_order_items.product_name) END",
+		groupBy: "sale_order_items.product_id",
+		orderBy: "revenue DESC",
+	},
+	"category": {
+		joins: []string{
+			"LEFT JOIN products ON products.id = sale_order_items.product_id",
+			"LEFT JOIN categories ON categories.id = products.category_id",
+		},
+		id:      "categories.id",
+		label:   "COALESCE(categories.name, 'Uncategorized')",
+		groupBy: "categories.id, categories.name",
+		orderBy: "revenue DESC",
+	},
+	"cashier": {
+		joins:   []string{"JOIN users ON users.id = sale_orders.created_by_id"},
+		id:      "users.id",
+		label:   "users.name",
+		groupBy: "users.id, users.name",
+		orderBy: "revenue DESC",
+	},
+	"day": {
+		id:      "NULL",
+		label:   "TO_CHAR(DATE(sale_orders.created_at), 'YYYY-MM-DD')",
+		groupBy: "DATE(sale_orders.created_at)",
+		orderBy: "label ASC",
+	},
+}
+
+// GetMargins reports revenue, cost of goods and gross margin of sale orders grouped by
+// ?group_by= product (default), category, cashier or day. Costs come from the unit cost
+// snapshot taken when each item was sold. Supported filters: from, to, outlet_id and
+// category_id, which includes the items of its subcategories.
+func (h *ReportHandler) GetMargins(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", "product")
+	grouping, found := marginGroupings[groupBy]
+	if !found {
+		utils.BadRequestResponse(c, "group_by must be one of product, category, cashier, day")
+		return
+	}
+
+	outlets, ok := listOutletScope(c, h.DB.WithContext(c))
+	if !ok {
+		return
+	}
+
+	query := h.DB.WithContext(c).Model(&models.SaleOrderItem{}).
+		Joins("JOIN sale_orders ON sale_orders.id = sale_order_items.sale_order_id AND sale_orders.deleted_at IS NULL")
+	for _, join := range grouping.joins {
+		query = query.Joins(join)
+	}
+	query = outlets.apply(query, "sale_orders.outlet_id")
+
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		id, err := strconv.ParseUint(categoryID, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid category_id")
+			return
+		}
+		categoryIDs, err := categorySubtreeIDs(h.DB.WithContext(c), uint(id))
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to fetch categories")
+			return
+		}
+		// Products deleted since the sale still count towards their category
+		query = query.Where("sale_order_items.product_id IN (?)",
+			h.DB.WithContext(c).Unscoped().Model(&models.Product{}).Select("id").Where("category_id IN ?", categoryIDs))
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := parseTimeQuery(from)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid from, use YYYY-MM-DD or RFC3339")
+			return
+		}
+		query = query.Where("sale_orders.created_at >= ?", t)
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := parseTimeQuery(to)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid to, use YYYY-MM-DD or RFC3339")
+			return
+		}
+		// A plain date includes the whole day
+		if len(to) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		query = query.Where("sale_orders.created_at < ?", t)
+	}
+
+	rows := []MarginReportRow{}
+	if err := query.Select(grouping.id + " AS id, " + grouping.label + " AS label, " +
+		"SUM(sale_order_items.quantity) AS quantity, " +
+		"SUM(sale_order_items.subtotal) AS revenue, " +
+		"SUM(sale_order_items.quantity * sale_order_items.list_price - sale_order_items.subtotal) AS discount, " +
+		"SUM(sale_order_items.quantity * sale_order_items.unit_cost) AS cost").
+		Group(grouping.groupBy).
+		Order(grouping.orderBy).
+		Scan(&rows).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to build margin report")
+		return
+	}
+
+	total := MarginReportRow{Label: "Total"}
+	for i := range rows {
+		rows[i].calculateMargin()
+		total.Quantity += rows[i].Quantity
+		total.Revenue += rows[i].Revenue
+		total.Discount += rows[i].Discount
+		total.Cost += rows[i].Cost
+	}
+	total.calculateMargin()
+
+	utils.OKResponse(c, "Margin report retrieved successfully", MarginReportResponse{
+		GroupBy: groupBy,
+		Rows:    rows,
+		Total:   total,
+	})
+}
+
+// calculateMargin fills the gross margin and its share of revenue
+func (r *MarginReportRow) calculateMargin() {
+	r.GrossMargin = r.Revenue - r.Cost
+	if r.Revenue != 0 {
+		r.MarginPercent = r.GrossMargin / r.Revenue * 100
+	}
+}
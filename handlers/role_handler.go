@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"regexp"
+	"strconv"
+
+	"interview-user/middleware"
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RoleHandler struct {
+	DB *gorm.DB
+}
+
+func NewRoleHandler(db *gorm.DB) *RoleHandler {
+	return &RoleHandler{DB: db}
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type CreateRoleRequest struct {
+	Name        string              `json:"name" binding:"required,min=2,max=50"`
+	Description string              `json:"description" binding:"max=255"`
+	Permissions []models.Permission `json:"permissions" binding:"required"`
+}
+
+type UpdateRoleRequest struct {
+	Description *string              `json:"description" binding:"omitempty,max=255"`
+	Permissions *[]models.Permission `json:"permissions"`
+}
+
+type RoleResponse struct {
+	ID          uint                `json:"id"`
+	Name        models.Role         `json:"name"`
+	Description string              `json:"description"`
+	IsSystem    bool                `json:"is_system"`
+	Permissions []models.Permission `json:"permissions"`
+	CreatedAt   string              `json:"created_at"`
+	UpdatedAt   string              `json:"updated_at"`
+}
+
+func toRoleResponse(role models.RoleDefinition) RoleResponse {
+	permissions := make([]models.Permission, 0, len(role.Permissions))
+	if role.Name == models.RoleOwner {
+		permissions = append(permissions, models.AllPermissions...)
+	} else {
+		for _, p := range role.Permissions {
+			permissions = append(permissions, p.Permission)
+		}
+	}
+
+	return RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:   role.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// invalidPermission returns the first permission in the list that is not known
+func invalidPermission(permissions []models.Permission) (models.Permission, bool) {
+	for _, p := range permissions {
+		if !models.IsValidPermission(p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// GetPermissions returns every permission that can be assigned to a role
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	utils.OKResponse(c, "Permissions retrieved successfully", models.AllPermissions)
+}
+
+// GetAll returns all roles with their permissions
+func (h *RoleHandler) GetAll(c *gin.Context) {
+	var roles []models.RoleDefinition
+	if err := h.DB.WithContext(c).Preload("Permissions").Order("id ASC").Find(&roles).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch roles")
+		return
+	}
+
+	items := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		items = append(items, toRoleResponse(role))
+	}
+
+	utils.OKResponse(c, "Roles retrieved successfully", items)
+}
+
+// GetByID returns a role by ID
+func (h *RoleHandler) GetByID(c *gin.Context) {
+	role, ok := h.findRole(c)
+	if !ok {
+		return
+	}
+
+	utils.OKResponse(c, "Role retrieved successfully", toRoleResponse(*role))
+}
+
+// Create creates a custom role with the given permissions
+func (h *RoleHandler) Create(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if !roleNamePattern.MatchString(req.Name) {
+		utils.BadRequestResponse(c, "name must start with a lowercase letter and contain only lowercase letters, digits and underscores")
+		return
+	}
+
+	if p, found := invalidPermission(req.Permissions); found {
+		utils.BadRequestResponse(c, "Unknown permission: "+string(p))
+		return
+	}
+
+	var existing models.RoleDefinition
+	if err := h.DB.WithContext(c).Where("name = ?", req.Name).First(&existing).Error; err == nil {
+		utils.BadRequestResponse(c, "Role already exists")
+		return
+	}
+
+	role := models.RoleDefinition{
+		Name:        models.Role(req.Name),
+		Description: req.Description,
+	}
+	for _, p := range uniquePermissions(req.Permissions) {
+		role.Permissions = append(role.Permissions, models.RolePermission{Permission: p})
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityRole, role.ID, nil, toRoleResponse(role))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create role")
+		return
+	}
+
+	middleware.InvalidatePermissionCache()
+	utils.CreatedResponse(c, "Role created successfully", toRoleResponse(role))
+}
+
+// Update changes a role's description and/or replaces its permissions
+func (h *RoleHandler) Update(c *gin.Context) {
+	role, ok := h.findRole(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if req.Permissions != nil {
+		if role.Name == models.RoleOwner {
+			utils.BadRequestResponse(c, "Owner role permissions cannot be changed")
+			return
+		}
+		if p, found := invalidPermission(*req.Permissions); found {
+			utils.BadRequestResponse(c, "Unknown permission: "+string(p))
+			return
+		}
+	}
+
+	before := toRoleResponse(*role)
+
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+
+		if req.Permissions != nil {
+			if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+				return err
+			}
+
+			role.Permissions = nil
+			for _, p := range uniquePermissions(*req.Permissions) {
+				role.Permissions = append(role.Permissions, models.RolePermission{RoleID: role.ID, Permission: p})
+			}
+			if len(role.Permissions) > 0 {
+				if err := tx.Create(&role.Permissions).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityRole, role.ID, before, toRoleResponse(*role))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update role")
+		return
+	}
+
+	middleware.InvalidatePermissionCache()
+	utils.OKResponse(c, "Role updated successfully", toRoleResponse(*role))
+}
+
+// Delete removes a custom role that is not assigned to any user, including users in the
+// trash, who would otherwise come back with a role that no longer exists
+func (h *RoleHandler) Delete(c *gin.Context) {
+	role, ok := h.findRole(c)
+	if !ok {
+		return
+	}
+
+	if role.IsSystem {
+		utils.BadRequestResponse(c, "System roles cannot be deleted")
+		return
+	}
+
+	var assigned int64
+	if err := h.DB.WithContext(c).Unscoped().Model(&models.User{}).Where("role = ?", role.Name).Count(&assigned).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count users")
+		return
+	}
+	if assigned > 0 {
+		utils.BadRequestResponse(c, "Role is still assigned to users, including deleted users in the trash")
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(role).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntityRole, role.ID, toRoleResponse(*role), nil)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete role")
+		return
+	}
+
+	middleware.InvalidatePermissionCache()
+	utils.OKResponse(c, "Role deleted successfully", nil)
+}
+
+// findRole loads the role referenced by the :id param, writing an error response on failure
+func (h *RoleHandler) findRole(c *gin.Context) (*models.RoleDefinition, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid role ID")
+		return nil, false
+	}
+
+	var role models.RoleDefinition
+	if err := h.DB.WithContext(c).Preload("Permissions").First(&role, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Role not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch role")
+		return nil, false
+	}
+
+	return &role, true
+}
+
+func uniquePermissions(permissions []models.Permission) []models.Permission {
+	seen := make(map[models.Permission]bool, len(permissions))
+	result := make([]models.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	return result
+}
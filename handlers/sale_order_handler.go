@@ -1,10 +1,10 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
 	"time"
 
+	"interview-user/middleware"
 	"interview-user/models"
 	"interview-user/utils"
 
@@ -13,46 +13,261 @@ import (
 )
 
 type SaleOrderHandler struct {
-	DB *gorm.DB
+	DB           *gorm.DB
+	OrderNumbers utils.OrderNumberFormat
+	Credentials  *utils.CredentialThrottle
 }
 
-func NewSaleOrderHandler(db *gorm.DB) *SaleOrderHandler {
-	return &SaleOrderHandler{DB: db}
+func NewSaleOrderHandler(db *gorm.DB, orderNumbers utils.OrderNumberFormat, credentials *utils.CredentialThrottle) *SaleOrderHandler {
+	return &SaleOrderHandler{
+		DB:           db,
+		OrderNumbers: orderNumbers,
+		Credentials:  credentials,
+	}
 }
 
 type CreateSaleOrderRequest struct {
 	CustomerName string                       `json:"customer_name" binding:"required"`
 	Notes        string                       `json:"notes"`
+	OutletID     *uint                        `json:"outlet_id"`
 	Items        []CreateSaleOrderItemRequest `json:"items" binding:"required,min=1"`
 }
 
+// CreateSaleOrderItemRequest is a catalog product or a free-text item. For products the
+// name and unit price default to the catalog values, and the unit price includes the
+// picked variant and modifiers; free-text items need both name and price. A unit_price
+// sent by the client replaces the computed price.
 type CreateSaleOrderItemRequest struct {
-	ProductName string  `json:"product_name" binding:"required"`
-	Quantity    int     `json:"quantity" binding:"required,min=1"`
-	UnitPrice   float64 `json:"unit_price" binding:"required,min=0"`
+	ProductID   *uint    `json:"product_id"`
+	VariantID   *uint    `json:"variant_id"`
+	ModifierIDs []uint   `json:"modifier_ids"`
+	ProductName string   `json:"product_name" binding:"required_without=ProductID"`
+	Quantity    int      `json:"quantity" binding:"required,min=1"`
+	UnitPrice   *float64 `json:"unit_price" binding:"omitempty,min=0"`
+	WeightGrams *int     `json:"weight_grams" binding:"omitempty,min=1"`
 }
 
+// UpdateSaleOrderRequest follows PATCH semantics: omitted fields are left untouched,
+// an empty notes string clears the notes and items, when given, replace all items.
 type UpdateSaleOrderRequest struct {
-	CustomerName string                       `json:"customer_name"`
-	Notes        string                       `json:"notes"`
-	Items        []CreateSaleOrderItemRequest `json:"items"`
+	CustomerName *string                      `json:"customer_name" binding:"omitempty,min=1"`
+	Notes        *string                      `json:"notes"`
+	Items        []CreateSaleOrderItemRequest `json:"items" binding:"omitempty,min=1,dive"`
+}
+
+// scopeOrders limits query to the orders of outlets the caller may access.
+// Users without sale_order.all only see orders they created themselves.
+func (h *SaleOrderHandler) scopeOrders(c *gin.Context, query *gorm.DB, outlets outletScope) *gorm.DB {
+	query = outlets.apply(query, "outlet_id")
+	if middleware.HasPermission(c, h.DB.WithContext(c), models.PermSaleOrderAll) {
+		return query
+	}
+	userID, _ := c.Get("user_id")
+	return query.Where("created_by_id = ?", userID)
+}
+
+// canAccessOrder reports whether the caller may read or modify order
+func (h *SaleOrderHandler) canAccessOrder(c *gin.Context, order *models.SaleOrder) bool {
+	if !callerOutletScope(c, h.DB.WithContext(c)).allows(order.OutletID) {
+		return false
+	}
+	if middleware.HasPermission(c, h.DB.WithContext(c), models.PermSaleOrderAll) {
+		return true
+	}
+	userID, _ := c.Get("user_id")
+	return order.CreatedByID == userID.(uint)
+}
+
+// findOrderForChange loads the order referenced by the :id param with its items for a
+// PATCH or DELETE, checking the caller may access it and that If-Match holds its version
+func (h *SaleOrderHandler) findOrderForChange(c *gin.Context) (*models.SaleOrder, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid sale order ID")
+		return nil, false
+	}
+
+	var order models.SaleOrder
+	if err := h.DB.WithContext(c).Preload("SaleOrderItems.Modifiers").First(&order, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Sale order not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch sale order")
+		return nil, false
+	}
+
+	if !h.canAccessOrder(c, &order) {
+		utils.ForbiddenResponse(c, "You can only access your own sale orders")
+		return nil, false
+	}
+
+	if !utils.CheckIfMatch(c, order.Version) {
+		return nil, false
+	}
+
+	return &order, true
+}
+
+// saveOrderChange runs change and persists order as a new revision in one transaction,
+// then responds with the reloaded order. before is the order state prior to the change.
+func (h *SaleOrderHandler) saveOrderChange(c *gin.Context, order *models.SaleOrder, before SaleOrderSnapshot, message string, change func(tx *gorm.DB) error) {
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := ensureInitialRevision(tx, order, before); err != nil {
+			return err
+		}
+		if err := change(tx); err != nil {
+			return err
+		}
+		if err := updateVersioned(tx, order, &order.Version); err != nil {
+			return err
+		}
+
+		after := newSaleOrderSnapshot(order)
+		if err := saveRevision(tx, c, order.ID, after); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntitySaleOrder, order.ID, before, after)
+	})
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "Sale order was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update sale order")
+		return
+	}
+
+	// Reload with associations
+	h.DB.WithContext(c).Preload("CreatedBy").Preload("ApprovedBy").Preload("SaleOrderItems.Modifiers").First(order, order.ID)
+
+	utils.SetETag(c, order.Version)
+	utils.OKResponse(c, message, order)
+}
+
+// Sensitive actions that can be approved by a supervisor
+const (
+	ApprovalActionVoid          = "void"
+	ApprovalActionAdjust        = "adjust"
+	ApprovalActionPriceOverride = "price_override"
+)
+
+// recordApproval stamps the supervisor who approved action on order.
+// Nothing is recorded when the caller was allowed to act without approval.
+func recordApproval(order *models.SaleOrder, approver *models.User, action string) {
+	if approver == nil {
+		return
+	}
+	now := time.Now()
+	order.ApprovedByID = &approver.ID
+	order.ApprovalAction = action
+	order.ApprovedAt = &now
+}
+
+// approvePriceOverride requires sale_order.price_override approval when one of items sells
+// below its catalog price, stamping the approving supervisor on order. It writes the error
+// response and returns false when not approved.
+func (h *SaleOrderHandler) approvePriceOverride(c *gin.Context, order *models.SaleOrder, items []models.SaleOrderItem) bool {
+	if !belowListPrice(items) {
+		return true
+	}
+	approver, ok := requireApproval(c, h.DB.WithContext(c), h.Credentials, models.PermSaleOrderPriceOverride)
+	if !ok {
+		return false
+	}
+	recordApproval(order, approver, ApprovalActionPriceOverride)
+	return true
+}
+
+// replaceSaleOrderItems soft deletes the order's current items and creates items in their
+// place, updating the order total and returning the old items' stock before taking the
+// new. It must run inside the caller's transaction.
+func replaceSaleOrderItems(tx *gorm.DB, c *gin.Context, order *models.SaleOrder, items []models.SaleOrderItem) error {
+	for i := range order.SaleOrderItems {
+		item := &order.SaleOrderItems[i]
+		if err := moveSaleItemStock(tx, c, order.ID, item, item.Quantity); err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("sale_order_id = ?", order.ID).Delete(&models.SaleOrderItem{}).Error; err != nil {
+		return err
+	}
+
+	var totalAmount float64
+	for i := range items {
+		items[i].SaleOrderID = order.ID
+		totalAmount += items[i].Subtotal
+	}
+	if err := tx.Create(&items).Error; err != nil {
+		return err
+	}
+	for i := range items {
+		if err := moveSaleItemStock(tx, c, order.ID, &items[i], -items[i].Quantity); err != nil {
+			return err
+		}
+	}
+
+	order.TotalAmount = totalAmount
+	order.SaleOrderItems = items
+	return nil
+}
+
+// createSaleOrder assigns the next order number and stores order with its first revision,
+// stamped with the terminal the request came from. It must run inside the caller's transaction.
+func createSaleOrder(tx *gorm.DB, c *gin.Context, orderNumbers utils.OrderNumberFormat, order *models.SaleOrder) error {
+	order.TerminalID = callerTerminalID(c)
+
+	store := unassignedStoreCode
+	if order.OutletID != nil {
+		var outlet models.Outlet
+		if err := tx.First(&outlet, *order.OutletID).Error; err != nil {
+			return err
+		}
+		store = outlet.Code
+	}
+
+	orderNumber, err := nextOrderNumber(tx, orderNumbers, store, time.Now())
+	if err != nil {
+		return err
+	}
+	order.OrderNumber = orderNumber
+
+	if err := tx.Create(order).Error; err != nil {
+		return err
+	}
+	for i := range order.SaleOrderItems {
+		item := &order.SaleOrderItems[i]
+		if err := moveSaleItemStock(tx, c, order.ID, item, -item.Quantity); err != nil {
+			return err
+		}
+	}
+	snapshot := newSaleOrderSnapshot(order)
+	if err := saveRevision(tx, c, order.ID, snapshot); err != nil {
+		return err
+	}
+	return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntitySaleOrder, order.ID, nil, snapshot)
 }
 
 // GetAll returns all sale orders with pagination
 func (h *SaleOrderHandler) GetAll(c *gin.Context) {
 	pagination := utils.GetPagination(c)
 
+	outlets, ok := listOutletScope(c, h.DB.WithContext(c))
+	if !ok {
+		return
+	}
+
 	var total int64
 	var orders []models.SaleOrder
 
 	// Count total
-	if err := h.DB.Model(&models.SaleOrder{}).Count(&total).Error; err != nil {
+	if err := h.scopeOrders(c, h.DB.WithContext(c).Model(&models.SaleOrder{}), outlets).Count(&total).Error; err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to count sale orders")
 		return
 	}
 
 	// Get paginated data
-	if err := h.DB.Preload("CreatedBy").Preload("SaleOrderItems").
+	if err := h.scopeOrders(c, h.DB.WithContext(c), outlets).Preload("CreatedBy").Preload("SaleOrderItems.Modifiers").
 		Order("created_at DESC").
 		Limit(pagination.Limit).
 		Offset(pagination.GetOffset()).
@@ -79,7 +294,7 @@ func (h *SaleOrderHandler) GetByID(c *gin.Context) {
 	}
 
 	var order models.SaleOrder
-	if err := h.DB.Preload("CreatedBy").Preload("SaleOrderItems").First(&order, id).Error; err != nil {
+	if err := h.DB.WithContext(c).Preload("CreatedBy").Preload("ApprovedBy").Preload("Outlet").Preload("SaleOrderItems.Modifiers").First(&order, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			utils.NotFoundResponse(c, "Sale order not found")
 			return
@@ -88,6 +303,12 @@ func (h *SaleOrderHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	if !h.canAccessOrder(c, &order) {
+		utils.ForbiddenResponse(c, "You can only access your own sale orders")
+		return
+	}
+
+	utils.SetETag(c, order.Version)
 	utils.OKResponse(c, "Sale order retrieved successfully", order)
 }
 
@@ -101,58 +322,50 @@ func (h *SaleOrderHandler) Create(c *gin.Context) {
 
 	userID, _ := c.Get("user_id")
 
-	// Calculate total amount
-	var totalAmount float64
-	var items []models.SaleOrderItem
-	for _, item := range req.Items {
-		subtotal := float64(item.Quantity) * item.UnitPrice
-		totalAmount += subtotal
-		items = append(items, models.SaleOrderItem{
-			ProductName: item.ProductName,
-			Quantity:    item.Quantity,
-			UnitPrice:   item.UnitPrice,
-			Subtotal:    subtotal,
-		})
+	// Orders belong to the caller's outlet unless another one is picked
+	outletID, ok := resolveOutletAssignment(c, h.DB.WithContext(c), req.OutletID, assignedOutletID(c))
+	if !ok {
+		return
 	}
 
-	// Generate order number
-	orderNumber := fmt.Sprintf("SO-%s-%d", time.Now().Format("20060102150405"), userID.(uint))
+	// Calculate total amount
+	items, totalAmount, ok := buildSaleOrderItemsOrRespond(c, h.DB.WithContext(c), req.Items)
+	if !ok {
+		return
+	}
 
 	order := models.SaleOrder{
-		OrderNumber:    orderNumber,
 		CustomerName:   req.CustomerName,
 		TotalAmount:    totalAmount,
 		Notes:          req.Notes,
 		CreatedByID:    userID.(uint),
+		OutletID:       outletID,
 		SaleOrderItems: items,
 	}
 
-	if err := h.DB.Create(&order).Error; err != nil {
+	if !h.approvePriceOverride(c, &order, items) {
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		return createSaleOrder(tx, c, h.OrderNumbers, &order)
+	})
+	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create sale order")
 		return
 	}
 
 	// Reload with associations
-	h.DB.Preload("CreatedBy").Preload("SaleOrderItems").First(&order, order.ID)
+	h.DB.WithContext(c).Preload("CreatedBy").Preload("SaleOrderItems.Modifiers").First(&order, order.ID)
 
+	utils.SetETag(c, order.Version)
 	utils.CreatedResponse(c, "Sale order created successfully", order)
 }
 
-// Update updates a sale order
+// Update partially updates a sale order
 func (h *SaleOrderHandler) Update(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		utils.BadRequestResponse(c, "Invalid sale order ID")
-		return
-	}
-
-	var order models.SaleOrder
-	if err := h.DB.Preload("SaleOrderItems").First(&order, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Sale order not found")
-			return
-		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch sale order")
+	order, ok := h.findOrderForChange(c)
+	if !ok {
 		return
 	}
 
@@ -162,69 +375,92 @@ func (h *SaleOrderHandler) Update(c *gin.Context) {
 		return
 	}
 
-	// Update fields
-	order.CustomerName = req.CustomerName
-	order.Notes = req.Notes
+	before := newSaleOrderSnapshot(order)
 
-	// If items are provided, update them
+	// Build replacement items, if provided
+	var items []models.SaleOrderItem
+	var totalAmount float64
 	if len(req.Items) > 0 {
-		// Delete existing items
-		h.DB.Where("sale_order_id = ?", order.ID).Delete(&models.SaleOrderItem{})
-
-		// Create new items
-		var totalAmount float64
-		var items []models.SaleOrderItem
-		for _, item := range req.Items {
-			subtotal := float64(item.Quantity) * item.UnitPrice
-			totalAmount += subtotal
-			items = append(items, models.SaleOrderItem{
-				SaleOrderID: order.ID,
-				ProductName: item.ProductName,
-				Quantity:    item.Quantity,
-				UnitPrice:   item.UnitPrice,
-				Subtotal:    subtotal,
-			})
-		}
-		order.TotalAmount = totalAmount
-		order.SaleOrderItems = items
-
-		if err := h.DB.Create(&items).Error; err != nil {
-			utils.InternalServerErrorResponse(c, "Failed to update sale order items")
+		items, totalAmount, ok = buildSaleOrderItemsOrRespond(c, h.DB.WithContext(c), req.Items)
+		if !ok {
 			return
 		}
 	}
 
-	if err := h.DB.Save(&order).Error; err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to update sale order")
+	if !h.approvePriceOverride(c, order, items) {
 		return
 	}
 
-	// Reload with associations
-	h.DB.Preload("CreatedBy").Preload("SaleOrderItems").First(&order, order.ID)
+	// Lowering the total (discounts, price overrides, removed items) needs approval
+	if len(items) > 0 && totalAmount < order.TotalAmount {
+		approver, ok := requireApproval(c, h.DB.WithContext(c), h.Credentials, models.PermSaleOrderAdjust)
+		if !ok {
+			return
+		}
+		recordApproval(order, approver, ApprovalActionAdjust)
+	}
 
-	utils.OKResponse(c, "Sale order updated successfully", order)
+	if req.CustomerName != nil {
+		order.CustomerName = *req.CustomerName
+	}
+	if req.Notes != nil {
+		order.Notes = *req.Notes
+	}
+
+	h.saveOrderChange(c, order, before, "Sale order updated successfully", func(tx *gorm.DB) error {
+		if len(items) > 0 {
+			return replaceSaleOrderItems(tx, c, order, items)
+		}
+		return nil
+	})
 }
 
 // Delete soft deletes a sale order
 func (h *SaleOrderHandler) Delete(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		utils.BadRequestResponse(c, "Invalid sale order ID")
+	order, ok := h.findOrderForChange(c)
+	if !ok {
 		return
 	}
 
-	var order models.SaleOrder
-	if err := h.DB.First(&order, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Sale order not found")
-			return
-		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch sale order")
+	approver, ok := requireApproval(c, h.DB.WithContext(c), h.Credentials, models.PermSaleOrderVoid)
+	if !ok {
 		return
 	}
 
-	// Soft delete the order and its items
-	if err := h.DB.Delete(&order).Error; err != nil {
+	// The snapshot includes the approval so the audit trail shows who signed off the void
+	recordApproval(order, approver, ApprovalActionVoid)
+	before := newSaleOrderSnapshot(order)
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// Soft delete the order and its items with the same timestamp so a restore
+		// can tell them apart from items removed by earlier updates
+		now := time.Now()
+		columns := map[string]interface{}{"deleted_at": now}
+		if approver != nil {
+			columns["approved_by_id"] = order.ApprovedByID
+			columns["approval_action"] = order.ApprovalAction
+			columns["approved_at"] = order.ApprovedAt
+		}
+		if err := updateColumnsVersioned(tx, order, order.Version, columns); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SaleOrderItem{}).Where("sale_order_id = ?", order.ID).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		// Goods of a voided order go back on the shelf
+		for i := range order.SaleOrderItems {
+			item := &order.SaleOrderItems[i]
+			if err := moveSaleItemStock(tx, c, order.ID, item, item.Quantity); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntitySaleOrder, order.ID, before, nil)
+	})
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "Sale order was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to delete sale order")
 		return
 	}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"gorm.io/gorm"
+)
+
+var errInjected = errors.New("injected failure")
+
+// failOn registers a callback that fails every statement of kind on table
+func failOn(t *testing.T, db *gorm.DB, kind, table string) {
+	t.Helper()
+	fail := func(tx *gorm.DB) {
+		if tx.Statement.Schema != nil && tx.Statement.Schema.Table == table {
+			tx.AddError(errInjected)
+		}
+	}
+
+	callbacks := db.Callback()
+	var err error
+	switch kind {
+	case "create":
+		err = callbacks.Create().Before("gorm:create").Register("test:fail", fail)
+	case "update":
+		err = callbacks.Update().Before("gorm:update").Register("test:fail", fail)
+	default:
+		t.Fatalf("unknown callback kind %q", kind)
+	}
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+}
+
+// seedOrder creates a product with stock and an order of two of it
+func seedOrder(t *testing.T, env *testEnv) (models.Product, models.SaleOrder) {
+	t.Helper()
+	product := models.Product{SKU: "TEA", Name: "Tea", Price: 10, CostPrice: 4, Stock: 8, IsActive: true}
+	env.create(t, &product)
+
+	order := models.SaleOrder{
+		OrderNumber:  "SO-1",
+		CustomerName: "Alice",
+		TotalAmount:  20,
+		CreatedByID:  env.Owner.ID,
+		SaleOrderItems: []models.SaleOrderItem{
+			{ProductID: &product.ID, ProductName: "Tea", Quantity: 2, UnitPrice: 10, UnitCost: 4, Subtotal: 20},
+		},
+	}
+	env.create(t, &order)
+	return product, order
+}
+
+func TestUpdateSaleOrderRollsBackOnFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  string
+		table string
+	}{
+		{"item create after soft delete", "create", "sale_order_items"},
+		{"order update", "update", "sale_orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			product, order := seedOrder(t, env)
+
+			h := NewSaleOrderHandler(env.DB, utils.OrderNumberFormat{}, nil)
+			r := env.router()
+			r.PATCH("/sale-orders/:id", h.Update)
+
+			failOn(t, env.DB, tt.kind, tt.table)
+
+			body := UpdateSaleOrderRequest{Items: []CreateSaleOrderItemRequest{{ProductID: &product.ID, Quantity: 3}}}
+			w := do(r, http.MethodPatch, fmt.Sprintf("/sale-orders/%d", order.ID), body, map[string]string{"If-Match": `"1"`})
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body.String())
+			}
+
+			var stored models.SaleOrder
+			if err := env.tx().Preload("SaleOrderItems").First(&stored, order.ID).Error; err != nil {
+				t.Fatalf("reload order: %v", err)
+			}
+			if stored.TotalAmount != 20 {
+				t.Errorf("total_amount = %v, want 20", stored.TotalAmount)
+			}
+			if stored.Version != 1 {
+				t.Errorf("version = %d, want 1", stored.Version)
+			}
+			if len(stored.SaleOrderItems) != 1 || stored.SaleOrderItems[0].ID != order.SaleOrderItems[0].ID || stored.SaleOrderItems[0].Quantity != 2 {
+				t.Errorf("items = %+v, want the original item with quantity 2", stored.SaleOrderItems)
+			}
+
+			var stock models.Product
+			if err := env.tx().First(&stock, product.ID).Error; err != nil {
+				t.Fatalf("reload product: %v", err)
+			}
+			if stock.Stock != 8 {
+				t.Errorf("stock = %d, want 8", stock.Stock)
+			}
+
+			var revisions, movements int64
+			env.tx().Model(&models.SaleOrderRevision{}).Where("sale_order_id = ?", order.ID).Count(&revisions)
+			env.tx().Model(&models.StockMovement{}).Count(&movements)
+			if revisions != 0 || movements != 0 {
+				t.Errorf("revisions = %d, movements = %d, want none", revisions, movements)
+			}
+		})
+	}
+}
+
+func TestUpdateSaleOrderReplacesItems(t *testing.T) {
+	env := newTestEnv(t)
+	product, order := seedOrder(t, env)
+
+	h := NewSaleOrderHandler(env.DB, utils.OrderNumberFormat{}, nil)
+	r := env.router()
+	r.PATCH("/sale-orders/:id", h.Update)
+
+	body := UpdateSaleOrderRequest{Items: []CreateSaleOrderItemRequest{{ProductID: &product.ID, Quantity: 3}}}
+	w := do(r, http.MethodPatch, fmt.Sprintf("/sale-orders/%d", order.ID), body, map[string]string{"If-Match": `"1"`})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var stored models.SaleOrder
+	if err := env.tx().Preload("SaleOrderItems").First(&stored, order.ID).Error; err != nil {
+		t.Fatalf("reload order: %v", err)
+	}
+	if stored.TotalAmount != 30 || stored.Version != 2 {
+		t.Errorf("total_amount = %v, version = %d, want 30 and 2", stored.TotalAmount, stored.Version)
+	}
+	if len(stored.SaleOrderItems) != 1 || stored.SaleOrderItems[0].Quantity != 3 {
+		t.Errorf("items = %+v, want one item with quantity 3", stored.SaleOrderItems)
+	}
+
+	var stock models.Product
+	env.tx().First(&stock, product.ID)
+	if stock.Stock != 7 {
+		t.Errorf("stock = %d, want 7", stock.Stock)
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"strconv"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type UpdateSaleOrderItemRequest struct {
+	Quantity  *int     `json:"quantity" binding:"omitempty,min=1"`
+	UnitPrice *float64 `json:"unit_price" binding:"omitempty,min=0"`
+}
+
+// recalculateSaleOrderTotal sets the order total from its loaded items
+func recalculateSaleOrderTotal(order *models.SaleOrder) {
+	var totalAmount float64
+	for _, item := range order.SaleOrderItems {
+		totalAmount += item.Subtotal
+	}
+	order.TotalAmount = totalAmount
+}
+
+// findOrderItem returns the index of the item referenced by the :itemId param in the loaded order
+func findOrderItem(c *gin.Context, order *models.SaleOrder) (int, bool) {
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid sale order item ID")
+		return 0, false
+	}
+
+	for i, item := range order.SaleOrderItems {
+		if item.ID == uint(itemID) {
+			return i, true
+		}
+	}
+
+	utils.NotFoundResponse(c, "Sale order item not found")
+	return 0, false
+}
+
+// AddItem adds a single item to a sale order
+func (h *SaleOrderHandler) AddItem(c *gin.Context) {
+	order, ok := h.findOrderForChange(c)
+	if !ok {
+		return
+	}
+
+	var req CreateSaleOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := newSaleOrderSnapshot(order)
+
+	items, _, ok := buildSaleOrderItemsOrRespond(c, h.DB.WithContext(c), []CreateSaleOrderItemRequest{req})
+	if !ok {
+		return
+	}
+	item := items[0]
+	item.SaleOrderID = order.ID
+
+	if !h.approvePriceOverride(c, order, items) {
+		return
+	}
+
+	h.saveOrderChange(c, order, before, "Sale order item added successfully", func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		if err := moveSaleItemStock(tx, c, order.ID, &item, -item.Quantity); err != nil {
+			return err
+		}
+		order.SaleOrderItems = append(order.SaleOrderItems, item)
+		recalculateSaleOrderTotal(order)
+		return nil
+	})
+}
+
+// UpdateItem changes the quantity or unit price of a single sale order item.
+// Lowering the item subtotal needs sale_order.adjust approval, and a unit price below
+// the catalog price needs sale_order.price_override approval.
+func (h *SaleOrderHandler) UpdateItem(c *gin.Context) {
+	order, ok := h.findOrderForChange(c)
+	if !ok {
+		return
+	}
+
+	index, ok := findOrderItem(c, order)
+	if !ok {
+		return
+	}
+
+	var req UpdateSaleOrderItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := newSaleOrderSnapshot(order)
+
+	item := &order.SaleOrderItems[index]
+	previousSubtotal := item.Subtotal
+	previousQuantity := item.Quantity
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.UnitPrice != nil {
+		item.UnitPrice = *req.UnitPrice
+	}
+	item.Subtotal = float64(item.Quantity) * item.UnitPrice
+
+	if req.UnitPrice != nil && !h.approvePriceOverride(c, order, []models.SaleOrderItem{*item}) {
+		return
+	}
+
+	if item.Subtotal < previousSubtotal {
+		approver, ok := requireApproval(c, h.DB.WithContext(c), h.Credentials, models.PermSaleOrderAdjust)
+		if !ok {
+			return
+		}
+		recordApproval(order, approver, ApprovalActionAdjust)
+	}
+
+	h.saveOrderChange(c, order, before, "Sale order item updated successfully", func(tx *gorm.DB) error {
+		if err := tx.Model(item).Select("Quantity", "UnitPrice", "Subtotal").Updates(item).Error; err != nil {
+			return err
+		}
+		if err := moveSaleItemStock(tx, c, order.ID, item, previousQuantity-item.Quantity); err != nil {
+			return err
+		}
+		recalculateSaleOrderTotal(order)
+		return nil
+	})
+}
+
+// RemoveItem soft deletes a single sale order item. The last item cannot be removed and
+// removing an item needs sale_order.adjust approval since it lowers the total.
+func (h *SaleOrderHandler) RemoveItem(c *gin.Context) {
+	order, ok := h.findOrderForChange(c)
+	if !ok {
+		return
+	}
+
+	index, ok := findOrderItem(c, order)
+	if !ok {
+		return
+	}
+
+	if len(order.SaleOrderItems) == 1 {
+		utils.BadRequestResponse(c, "Sale order must keep at least one item, delete the order instead")
+		return
+	}
+
+	before := newSaleOrderSnapshot(order)
+	item := order.SaleOrderItems[index]
+
+	if item.Subtotal > 0 {
+		approver, ok := requireApproval(c, h.DB.WithContext(c), h.Credentials, models.PermSaleOrderAdjust)
+		if !ok {
+			return
+		}
+		recordApproval(order, approver, ApprovalActionAdjust)
+	}
+
+	h.saveOrderChange(c, order, before, "Sale order item removed successfully", func(tx *gorm.DB) error {
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		if err := moveSaleItemStock(tx, c, order.ID, &item, item.Quantity); err != nil {
+			return err
+		}
+		order.SaleOrderItems = append(order.SaleOrderItems[:index], order.SaleOrderItems[index+1:]...)
+		recalculateSaleOrderTotal(order)
+		return nil
+	})
+}
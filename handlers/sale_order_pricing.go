@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// buildSaleOrderItems converts requested items into order items and returns their total.
+// Product items take the catalog name and price unless overridden, and snapshot the
+// catalog price and the product's current cost so discounts and margins stay correct
+// when the catalog changes later. Free-text items list at the price they are sold for.
+// It returns a client-facing message when an item is invalid.
+func buildSaleOrderItems(db *gorm.DB, reqItems []CreateSaleOrderItemRequest) ([]models.SaleOrderItem, float64, string, error) {
+	var productIDs []uint
+	for _, item := range reqItems {
+		if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+			continue
+		}
+		if item.VariantID != nil || len(item.ModifierIDs) > 0 || item.WeightGrams != nil {
+			return nil, 0, "variant_id, modifier_ids and weight_grams need a product_id", nil
+		}
+		if item.UnitPrice == nil {
+			return nil, 0, "unit_price is required for items without product_id", nil
+		}
+	}
+
+	products, err := loadSaleProducts(db, productIDs)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	var totalAmount float64
+	var items []models.SaleOrderItem
+	for _, reqItem := range reqItems {
+		item := models.SaleOrderItem{
+			ProductID:   reqItem.ProductID,
+			ProductName: reqItem.ProductName,
+			Quantity:    reqItem.Quantity,
+		}
+		if reqItem.ProductID != nil {
+			product, found := products[*reqItem.ProductID]
+			if !found {
+				return nil, 0, "Product not found or inactive", nil
+			}
+			if message := priceProductItem(&item, product, reqItem); message != "" {
+				return nil, 0, message, nil
+			}
+		}
+		if reqItem.UnitPrice != nil {
+			item.UnitPrice = *reqItem.UnitPrice
+		}
+		if reqItem.ProductID == nil {
+			item.ListPrice = item.UnitPrice
+		}
+		item.Subtotal = float64(item.Quantity) * item.UnitPrice
+		totalAmount += item.Subtotal
+		items = append(items, item)
+	}
+	return items, totalAmount, "", nil
+}
+
+// buildSaleOrderItemsOrRespond is buildSaleOrderItems for handlers, writing the error response on failure
+func buildSaleOrderItemsOrRespond(c *gin.Context, db *gorm.DB, reqItems []CreateSaleOrderItemRequest) ([]models.SaleOrderItem, float64, bool) {
+	items, totalAmount, message, err := buildSaleOrderItems(db, reqItems)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch products")
+		return nil, 0, false
+	}
+	if message != "" {
+		utils.BadRequestResponse(c, message)
+		return nil, 0, false
+	}
+	return items, totalAmount, true
+}
+
+// loadSaleProducts loads the active products with their active variants and modifiers
+func loadSaleProducts(db *gorm.DB, productIDs []uint) (map[uint]models.Product, error) {
+	products := make(map[uint]models.Product, len(productIDs))
+	if len(productIDs) == 0 {
+		return products, nil
+	}
+
+	var found []models.Product
+	if err := db.Preload("Variants", "is_active = ?", true).
+		Preload("ModifierGroups.Modifiers", "is_active = ?", true).
+		Where("id IN ? AND is_active = ?", productIDs, true).
+		Find(&found).Error; err != nil {
+		return nil, err
+	}
+	for _, product := range found {
+		products[product.ID] = product
+	}
+	return products, nil
+}
+
+// priceProductItem fills item from product and the picked variant and modifiers:
+// the unit price is the variant price, or the product price without variants, plus
+// the price delta of every modifier. For a weighed item the catalog price and cost
+// are per kilogram and get scaled to the weight. It returns a client-facing message
+// when the variant, modifier or weight is invalid.
+func priceProductItem(item *models.SaleOrderItem, product models.Product, reqItem CreateSaleOrderItemRequest) string {
+	if reqItem.WeightGrams != nil && !product.SoldByWeight {
+		return fmt.Sprintf("%s is not sold by weight", product.Name)
+	}
+	if item.ProductName == "" {
+		item.ProductName = product.Name
+		if reqItem.WeightGrams != nil {
+			item.ProductName = fmt.Sprintf("%s %.3f kg", product.Name, float64(*reqItem.WeightGrams)/1000)
+		}
+	}
+	item.UnitPrice = product.Price
+	item.UnitCost = product.CostPrice
+
+	if reqItem.VariantID != nil {
+		variant, found := findVariant(product.Variants, *reqItem.VariantID)
+		if !found {
+			return "Variant not found for this product"
+		}
+		item.VariantID = &variant.ID
+		item.VariantName = variant.Name
+		item.UnitPrice = variant.Price
+	} else if len(product.Variants) > 0 {
+		return fmt.Sprintf("variant_id is required for %s", product.Name)
+	}
+	if reqItem.WeightGrams != nil {
+		item.WeightGrams = reqItem.WeightGrams
+		item.UnitPrice = priceForWeight(item.UnitPrice, *reqItem.WeightGrams)
+		item.UnitCost = priceForWeight(item.UnitCost, *reqItem.WeightGrams)
+	}
+
+	modifiers, message := selectModifiers(product, reqItem.ModifierIDs)
+	if message != "" {
+		return message
+	}
+	item.Modifiers = modifiers
+	for _, modifier := range modifiers {
+		item.UnitPrice += modifier.PriceDelta
+	}
+	item.ListPrice = item.UnitPrice
+	return ""
+}
+
+// belowListPrice reports whether any item sells below its catalog price
+func belowListPrice(items []models.SaleOrderItem) bool {
+	for _, item := range items {
+		if item.UnitPrice < item.ListPrice {
+			return true
+		}
+	}
+	return false
+}
+
+// priceForWeight scales a price per kilogram to grams, rounded to cents
+func priceForWeight(pricePerKg float64, grams int) float64 {
+	return math.Round(pricePerKg*float64(grams)/1000*100) / 100
+}
+
+func findVariant(variants []models.ProductVariant, id uint) (models.ProductVariant, bool) {
+	for _, variant := range variants {
+		if variant.ID == id {
+			return variant, true
+		}
+	}
+	return models.ProductVariant{}, false
+}
+
+// selectModifiers checks modifierIDs against the product's modifier groups and their
+// selection limits, and returns the modifiers to store on the item
+func selectModifiers(product models.Product, modifierIDs []uint) ([]models.SaleOrderItemModifier, string) {
+	type option struct {
+		group    int
+		modifier models.Modifier
+	}
+	options := make(map[uint]option)
+	for g, group := range product.ModifierGroups {
+		for _, modifier := range group.Modifiers {
+			options[modifier.ID] = option{group: g, modifier: modifier}
+		}
+	}
+
+	picked := make(map[uint]bool, len(modifierIDs))
+	counts := make([]int, len(product.ModifierGroups))
+	var selected []models.SaleOrderItemModifier
+	for _, id := range modifierIDs {
+		opt, found := options[id]
+		if !found {
+			return nil, "Modifier is not available for this product"
+		}
+		if picked[id] {
+			return nil, "Modifier can only be selected once"
+		}
+		picked[id] = true
+		counts[opt.group]++
+		selected = append(selected, models.SaleOrderItemModifier{
+			ModifierID: opt.modifier.ID,
+			Name:       opt.modifier.Name,
+			PriceDelta: opt.modifier.PriceDelta,
+		})
+	}
+
+	for g, group := range product.ModifierGroups {
+		if counts[g] < group.MinSelections {
+			return nil, fmt.Sprintf("Select at least %d option(s) of %s", group.MinSelections, group.Name)
+		}
+		if group.MaxSelections > 0 && counts[g] > group.MaxSelections {
+			return nil, fmt.Sprintf("Select at most %d option(s) of %s", group.MaxSelections, group.Name)
+		}
+	}
+	return selected, ""
+}
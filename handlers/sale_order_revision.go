@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaleOrderSnapshot is the recorded state of a sale order header and its items.
+// It is stored in revisions and used as the audit log state of an order.
+type SaleOrderSnapshot struct {
+	OrderNumber    string                  `json:"order_number"`
+	CustomerName   string                  `json:"customer_name"`
+	Notes          string                  `json:"notes"`
+	TotalAmount    float64                 `json:"total_amount"`
+	CreatedByID    uint                    `json:"created_by_id"`
+	ApprovedByID   *uint                   `json:"approved_by_id"`
+	ApprovalAction string                  `json:"approval_action"`
+	Items          []SaleOrderItemSnapshot `json:"items"`
+}
+
+// SaleOrderItemSnapshot is the recorded state of one item. ID and VariantID are missing
+// from snapshots taken before they were recorded.
+type SaleOrderItemSnapshot struct {
+	ID          uint     `json:"id,omitempty"`
+	ProductID   *uint    `json:"product_id,omitempty"`
+	ProductName string   `json:"product_name"`
+	VariantID   *uint    `json:"variant_id,omitempty"`
+	VariantName string   `json:"variant_name,omitempty"`
+	Modifiers   []string `json:"modifiers,omitempty"`
+	Quantity    int      `json:"quantity"`
+	UnitPrice   float64  `json:"unit_price"`
+	Subtotal    float64  `json:"subtotal"`
+}
+
+type SaleOrderRevisionResponse struct {
+	RevisionNumber int               `json:"revision_number"`
+	CreatedByID    uint              `json:"created_by_id"`
+	CreatedBy      string            `json:"created_by"`
+	CreatedAt      string            `json:"created_at"`
+	Snapshot       SaleOrderSnapshot `json:"snapshot"`
+}
+
+type SaleOrderItemChange struct {
+	ItemID      uint                         `json:"item_id,omitempty"`
+	ProductName string                       `json:"product_name"`
+	Changes     map[string]utils.FieldChange `json:"changes"`
+}
+
+type SaleOrderRevisionDiffResponse struct {
+	From         int                          `json:"from"`
+	To           int                          `json:"to"`
+	Changes      map[string]utils.FieldChange `json:"changes"`
+	ItemsAdded   []SaleOrderItemSnapshot      `json:"items_added"`
+	ItemsRemoved []SaleOrderItemSnapshot      `json:"items_removed"`
+	ItemsChanged []SaleOrderItemChange        `json:"items_changed"`
+}
+
+func newSaleOrderSnapshot(order *models.SaleOrder) SaleOrderSnapshot {
+	items := make([]SaleOrderItemSnapshot, 0, len(order.SaleOrderItems))
+	for _, item := range order.SaleOrderItems {
+		var modifiers []string
+		for _, modifier := range item.Modifiers {
+			modifiers = append(modifiers, modifier.Name)
+		}
+		items = append(items, SaleOrderItemSnapshot{
+			ID:          item.ID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			VariantID:   item.VariantID,
+			VariantName: item.VariantName,
+			Modifiers:   modifiers,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Subtotal:    item.Subtotal,
+		})
+	}
+
+	return SaleOrderSnapshot{
+		OrderNumber:    order.OrderNumber,
+		CustomerName:   order.CustomerName,
+		Notes:          order.Notes,
+		TotalAmount:    order.TotalAmount,
+		CreatedByID:    order.CreatedByID,
+		ApprovedByID:   order.ApprovedByID,
+		ApprovalAction: order.ApprovalAction,
+		Items:          items,
+	}
+}
+
+// saveRevision stores snapshot as the next revision of the order
+func saveRevision(tx *gorm.DB, c *gin.Context, orderID uint, snapshot SaleOrderSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := lockSaleOrderRow(tx, orderID); err != nil {
+		return err
+	}
+
+	var latest int
+	if err := tx.Model(&models.SaleOrderRevision{}).
+		Where("sale_order_id = ?", orderID).
+		Select("COALESCE(MAX(revision_number), 0)").
+		Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("user_id")
+	return tx.Create(&models.SaleOrderRevision{
+		SaleOrderID:    orderID,
+		RevisionNumber: latest + 1,
+		Snapshot:       data,
+		CreatedByID:    userID.(uint),
+	}).Error
+}
+
+// ensureInitialRevision records the current state as revision 1 for orders created
+// before revisions were tracked, so their first update can still be compared.
+func ensureInitialRevision(tx *gorm.DB, order *models.SaleOrder, snapshot SaleOrderSnapshot) error {
+	if err := lockSaleOrderRow(tx, order.ID); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&models.SaleOrderRevision{}).Where("sale_order_id = ?", order.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.SaleOrderRevision{
+		SaleOrderID:    order.ID,
+		RevisionNumber: 1,
+		Snapshot:       data,
+		CreatedByID:    order.CreatedByID,
+		CreatedAt:      order.UpdatedAt,
+	}).Error
+}
+
+func toSaleOrderRevisionResponse(revision models.SaleOrderRevision) (SaleOrderRevisionResponse, error) {
+	response := SaleOrderRevisionResponse{
+		RevisionNumber: revision.RevisionNumber,
+		CreatedByID:    revision.CreatedByID,
+		CreatedAt:      revision.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if revision.CreatedBy != nil {
+		response.CreatedBy = revision.CreatedBy.Username
+	}
+	err := json.Unmarshal(revision.Snapshot, &response.Snapshot)
+	return response, err
+}
+
+// findAccessibleOrder loads the order referenced by the :id param and checks the caller may access it
+func (h *SaleOrderHandler) findAccessibleOrder(c *gin.Context) (*models.SaleOrder, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid sale order ID")
+		return nil, false
+	}
+
+	var order models.SaleOrder
+	if err := h.DB.WithContext(c).First(&order, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Sale order not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch sale order")
+		return nil, false
+	}
+
+	if !h.canAccessOrder(c, &order) {
+		utils.ForbiddenResponse(c, "You can only access your own sale orders")
+		return nil, false
+	}
+
+	return &order, true
+}
+
+// GetRevisions returns every revision of a sale order, oldest first
+func (h *SaleOrderHandler) GetRevisions(c *gin.Context) {
+	order, ok := h.findAccessibleOrder(c)
+	if !ok {
+		return
+	}
+
+	var revisions []models.SaleOrderRevision
+	if err := h.DB.WithContext(c).Preload("CreatedBy").
+		Where("sale_order_id = ?", order.ID).
+		Order("revision_number ASC").
+		Find(&revisions).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch sale order revisions")
+		return
+	}
+
+	items := make([]SaleOrderRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		response, err := toSaleOrderRevisionResponse(revision)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to read sale order revision")
+			return
+		}
+		items = append(items, response)
+	}
+
+	utils.OKResponse(c, "Sale order revisions retrieved successfully", items)
+}
+
+// DiffRevisions compares two revisions of a sale order given by the from and to query params
+func (h *SaleOrderHandler) DiffRevisions(c *gin.Context) {
+	order, ok := h.findAccessibleOrder(c)
+	if !ok {
+		return
+	}
+
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from < 1 {
+		utils.BadRequestResponse(c, "Invalid from revision")
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil || to < 1 {
+		utils.BadRequestResponse(c, "Invalid to revision")
+		return
+	}
+
+	var revisions []models.SaleOrderRevision
+	if err := h.DB.WithContext(c).Where("sale_order_id = ? AND revision_number IN ?", order.ID, []int{from, to}).
+		Find(&revisions).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch sale order revisions")
+		return
+	}
+
+	snapshots := make(map[int]SaleOrderSnapshot, 2)
+	for _, revision := range revisions {
+		var snapshot SaleOrderSnapshot
+		if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to read sale order revision")
+			return
+		}
+		snapshots[revision.RevisionNumber] = snapshot
+	}
+
+	fromSnapshot, fromFound := snapshots[from]
+	toSnapshot, toFound := snapshots[to]
+	if !fromFound || !toFound {
+		utils.NotFoundResponse(c, "Sale order revision not found")
+		return
+	}
+
+	diff, err := diffSaleOrderSnapshots(fromSnapshot, toSnapshot)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to compare sale order revisions")
+		return
+	}
+	diff.From = from
+	diff.To = to
+
+	utils.OKResponse(c, "Sale order revision diff retrieved successfully", diff)
+}
+
+// itemPairingKeys pair the items of two snapshots, tried in order on the items still
+// unpaired. Items edited in place keep their ID; items replaced by a full update get new
+// IDs and are paired by product and variant, and free-text items or snapshots taken
+// before IDs were recorded fall back to the names. An empty key never pairs.
+var itemPairingKeys = []func(item SaleOrderItemSnapshot) string{
+	func(item SaleOrderItemSnapshot) string {
+		if item.ID == 0 {
+			return ""
+		}
+		return strconv.FormatUint(uint64(item.ID), 10)
+	},
+	func(item SaleOrderItemSnapshot) string {
+		if item.ProductID == nil || (item.VariantID == nil && item.VariantName != "") {
+			return ""
+		}
+		variantID := uint(0)
+		if item.VariantID != nil {
+			variantID = *item.VariantID
+		}
+		return fmt.Sprintf("%d/%d", *item.ProductID, variantID)
+	},
+	func(item SaleOrderItemSnapshot) string {
+		return item.ProductName + "\x00" + item.VariantName
+	},
+}
+
+// diffSaleOrderSnapshots compares header fields and pairs items by itemPairingKeys
+func diffSaleOrderSnapshots(from, to SaleOrderSnapshot) (SaleOrderRevisionDiffResponse, error) {
+	fromItems, toItems := from.Items, to.Items
+	from.Items, to.Items = nil, nil
+
+	changes, err := utils.DiffJSON(from, to)
+	if err != nil {
+		return SaleOrderRevisionDiffResponse{}, err
+	}
+
+	diff := SaleOrderRevisionDiffResponse{
+		Changes:      changes,
+		ItemsAdded:   []SaleOrderItemSnapshot{},
+		ItemsRemoved: []SaleOrderItemSnapshot{},
+		ItemsChanged: []SaleOrderItemChange{},
+	}
+
+	// pairs[j] is the index of the from item paired with to item j, or -1
+	pairs := make([]int, len(toItems))
+	for j := range pairs {
+		pairs[j] = -1
+	}
+	paired := make([]bool, len(fromItems))
+	for _, key := range itemPairingKeys {
+		// Items with the same key are paired in order of appearance
+		remaining := make(map[string][]int)
+		for i, item := range fromItems {
+			if k := key(item); !paired[i] && k != "" {
+				remaining[k] = append(remaining[k], i)
+			}
+		}
+		for j, item := range toItems {
+			k := key(item)
+			if pairs[j] >= 0 || k == "" || len(remaining[k]) == 0 {
+				continue
+			}
+			pairs[j] = remaining[k][0]
+			paired[pairs[j]] = true
+			remaining[k] = remaining[k][1:]
+		}
+	}
+
+	for j, item := range toItems {
+		if pairs[j] < 0 {
+			diff.ItemsAdded = append(diff.ItemsAdded, item)
+			continue
+		}
+
+		// A new ID from replacing the items is not a change of the item itself
+		previous, current := fromItems[pairs[j]], item
+		previous.ID, current.ID = 0, 0
+		itemChanges, err := utils.DiffJSON(previous, current)
+		if err != nil {
+			return SaleOrderRevisionDiffResponse{}, err
+		}
+		if len(itemChanges) > 0 {
+			diff.ItemsChanged = append(diff.ItemsChanged, SaleOrderItemChange{
+				ItemID:      item.ID,
+				ProductName: item.ProductName,
+				Changes:     itemChanges,
+			})
+		}
+	}
+
+	for i, item := range fromItems {
+		if !paired[i] {
+			diff.ItemsRemoved = append(diff.ItemsRemoved, item)
+		}
+	}
+
+	return diff, nil
+}
+
+// lockSaleOrderRow locks the order row so concurrent transactions number their revisions one after another
+func lockSaleOrderRow(tx *gorm.DB, orderID uint) error {
+	var order models.SaleOrder
+	return tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&order, orderID).Error
+}
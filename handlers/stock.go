@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"interview-user/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applyStockMovement locks the product, changes its stock by movement.Quantity and
+// appends movement to the ledger. Incoming movements with a unit cost update the
+// product's moving average cost. Stock may go negative when sales outpace recorded
+// receipts; a stock take corrects it. Products sold by weight keep their stock but
+// still get their cost updated and the movement recorded.
+// It must run inside the caller's transaction.
+func applyStockMovement(tx *gorm.DB, movement *models.StockMovement) error {
+	var product models.Product
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, movement.ProductID).Error; err != nil {
+		return err
+	}
+
+	balance := product.Stock + movement.Quantity
+	updates := map[string]interface{}{"stock": balance}
+	if movement.Quantity > 0 && movement.UnitCost > 0 {
+		updates["cost_price"] = movingAverageCost(product.Stock, product.CostPrice, movement.Quantity, movement.UnitCost)
+	}
+	// Goods sold by weight can't be counted in whole units: their stock stays as is and
+	// there is nothing to average the cost against, so the latest cost is taken
+	if product.SoldByWeight {
+		balance = product.Stock
+		updates = map[string]interface{}{}
+		if movement.Quantity > 0 && movement.UnitCost > 0 {
+			updates["cost_price"] = movement.UnitCost
+		}
+	}
+	if len(updates) > 0 {
+		if err := tx.Unscoped().Model(&product).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+
+	movement.BalanceAfter = balance
+	return tx.Create(movement).Error
+}
+
+// movingAverageCost blends the cost of the units on hand with the cost of incoming units.
+// Stock that went negative carries no cost, so only the incoming cost is used then.
+func movingAverageCost(stock int, cost float64, quantity int, unitCost float64) float64 {
+	if stock <= 0 {
+		return unitCost
+	}
+	return (float64(stock)*cost + float64(quantity)*unitCost) / float64(stock+quantity)
+}
+
+// moveSaleItemStock changes the stock of a sale order item's product by quantity:
+// negative when goods leave with the order, positive when they come back because
+// the item was reduced, removed or the order voided. Free-text items have no stock.
+func moveSaleItemStock(tx *gorm.DB, c *gin.Context, orderID uint, item *models.SaleOrderItem, quantity int) error {
+	if item.ProductID == nil || quantity == 0 {
+		return nil
+	}
+
+	movementType := models.StockMovementSale
+	if quantity > 0 {
+		movementType = models.StockMovementSaleReturn
+	}
+
+	userID, _ := c.Get("user_id")
+	return applyStockMovement(tx, &models.StockMovement{
+		ProductID:     *item.ProductID,
+		Type:          movementType,
+		Quantity:      quantity,
+		UnitCost:      item.UnitCost,
+		ReferenceType: models.AuditEntitySaleOrder,
+		ReferenceID:   orderID,
+		CreatedByID:   userID.(uint),
+	})
+}
@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"interview-user/middleware"
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockTakeHandler struct {
+	DB *gorm.DB
+}
+
+func NewStockTakeHandler(db *gorm.DB) *StockTakeHandler {
+	return &StockTakeHandler{DB: db}
+}
+
+// CreateStockTakeRequest starts a count of the given products, the products of a
+// category and its subcategories, or every active product when neither is given
+type CreateStockTakeRequest struct {
+	Notes      string `json:"notes"`
+	CategoryID *uint  `json:"category_id"`
+	ProductIDs []uint `json:"product_ids"`
+}
+
+// SubmitStockTakeCountsRequest is one batch of counts. Counts of a product submitted in
+// several batches (e.g. two shelves) are added up; Replace overwrites earlier counts instead.
+type SubmitStockTakeCountsRequest struct {
+	Replace bool                          `json:"replace"`
+	Items   []SubmitStockTakeCountRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type SubmitStockTakeCountRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"min=0"`
+}
+
+// blindStockTake is a stock take shown to counting staff before it is approved. Expected
+// quantities, variances and product stock are left out so counts are not biased by them.
+type blindStockTake struct {
+	models.StockTake
+	Items []blindStockTakeItem `json:"items,omitempty"`
+}
+
+type blindStockTakeItem struct {
+	models.StockTakeItem
+	Product          *blindStockTakeProduct `json:"product,omitempty"`
+	ExpectedQuantity *int                   `json:"expected_quantity,omitempty"`
+	Variance         *int                   `json:"variance,omitempty"`
+}
+
+type blindStockTakeProduct struct {
+	ID      uint    `json:"id"`
+	SKU     string  `json:"sku"`
+	Barcode *string `json:"barcode"`
+	Name    string  `json:"name"`
+}
+
+// stockTakeView returns stockTake as it is sent to the caller. Only callers with
+// stock_take.manage see expected quantities and variances while the session is not approved.
+func (h *StockTakeHandler) stockTakeView(c *gin.Context, stockTake *models.StockTake) interface{} {
+	if stockTake.Status == models.StockTakeApproved || middleware.HasPermission(c, h.DB.WithContext(c), models.PermStockTakeManage) {
+		return stockTake
+	}
+
+	view := blindStockTake{StockTake: *stockTake, Items: make([]blindStockTakeItem, 0, len(stockTake.Items))}
+	for _, item := range stockTake.Items {
+		blind := blindStockTakeItem{StockTakeItem: item}
+		if item.Product != nil {
+			blind.Product = &blindStockTakeProduct{
+				ID:      item.Product.ID,
+				SKU:     item.Product.SKU,
+				Barcode: item.Product.Barcode,
+				Name:    item.Product.Name,
+			}
+		}
+		view.Items = append(view.Items, blind)
+	}
+	return view
+}
+
+// stockTakeError is a client error detected inside a stock take transaction
+type stockTakeError struct {
+	message string
+}
+
+func (e *stockTakeError) Error() string {
+	return e.message
+}
+
+// GetAll returns stock takes with pagination, optionally filtered by ?status=
+func (h *StockTakeHandler) GetAll(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	query := h.DB.WithContext(c).Model(&models.StockTake{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	var stockTakes []models.StockTake
+
+	if err := query.Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count stock takes")
+		return
+	}
+
+	if err := query.Preload("CreatedBy").
+		Order("created_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&stockTakes).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch stock takes")
+		return
+	}
+
+	utils.OKResponse(c, "Stock takes retrieved successfully", utils.PaginatedResponse{
+		Items:      stockTakes,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// GetByID returns a stock take with its items, expected and counted quantities and variances
+func (h *StockTakeHandler) GetByID(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+
+	stockTake, ok := h.loadStockTake(c, id)
+	if !ok {
+		return
+	}
+	utils.OKResponse(c, "Stock take retrieved successfully", h.stockTakeView(c, stockTake))
+}
+
+// Create opens a stock take session and captures the current stock of every product
+// in it as the expected quantity. Only one session can be open at a time so the same
+// stock is never adjusted twice.
+func (h *StockTakeHandler) Create(c *gin.Context) {
+	var req CreateStockTakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	stockTake := models.StockTake{
+		Status:      models.StockTakeOpen,
+		Notes:       req.Notes,
+		CreatedByID: userID.(uint),
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var open int64
+		if err := tx.Model(&models.StockTake{}).Where("status = ?", models.StockTakeOpen).Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return &stockTakeError{message: "Another stock take is still open, approve or cancel it first"}
+		}
+
+		query := tx.Where("is_active = ? AND sold_by_weight = ?", true, false)
+		if req.CategoryID != nil {
+			categoryIDs, err := categorySubtreeIDs(tx, *req.CategoryID)
+			if err != nil {
+				return err
+			}
+			query = query.Where("category_id IN ?", categoryIDs)
+		}
+		if len(req.ProductIDs) > 0 {
+			query = query.Where("id IN ?", req.ProductIDs)
+		}
+
+		// Lock the products so no sale or receipt changes stock while it is captured
+		var products []models.Product
+		if err := query.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id ASC").Find(&products).Error; err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			return &stockTakeError{message: "No active products to count"}
+		}
+
+		number, err := nextSequenceNumber(tx, stockTakeNumbers, stockTakeSequenceScope, "", time.Now())
+		if err != nil {
+			return err
+		}
+		stockTake.Number = number
+
+		for _, product := range products {
+			stockTake.Items = append(stockTake.Items, models.StockTakeItem{
+				ProductID:        product.ID,
+				ExpectedQuantity: product.Stock,
+			})
+		}
+		if err := tx.Create(&stockTake).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityStockTake, stockTake.ID, nil, stockTakeAuditState(&stockTake))
+	})
+	if err != nil {
+		// The unique index on open sessions rejects a session opened concurrently
+		var open int64
+		if h.DB.WithContext(c).Model(&models.StockTake{}).Where("status = ?", models.StockTakeOpen).Count(&open).Error == nil && open > 0 {
+			err = &stockTakeError{message: "Another stock take is still open, approve or cancel it first"}
+		}
+		h.respondWithError(c, err, "Failed to create stock take")
+		return
+	}
+
+	created, ok := h.loadStockTake(c, stockTake.ID)
+	if !ok {
+		return
+	}
+	utils.CreatedResponse(c, "Stock take created successfully", created)
+}
+
+// SubmitCounts records a batch of counted quantities in an open stock take
+func (h *StockTakeHandler) SubmitCounts(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+
+	var req SubmitStockTakeCountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	countedByID := userID.(uint)
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		stockTake, err := lockOpenStockTake(tx, id)
+		if err != nil {
+			return err
+		}
+
+		itemIndex := make(map[uint]int, len(stockTake.Items))
+		for i, item := range stockTake.Items {
+			itemIndex[item.ProductID] = i
+		}
+
+		now := time.Now()
+		for _, count := range req.Items {
+			i, found := itemIndex[count.ProductID]
+			if !found {
+				return &stockTakeError{message: "Product is not part of this stock take"}
+			}
+			item := &stockTake.Items[i]
+
+			counted := count.Quantity
+			if item.CountedQuantity != nil && !req.Replace {
+				counted += *item.CountedQuantity
+			}
+			variance := counted - item.ExpectedQuantity
+			item.CountedQuantity = &counted
+			item.Variance = &variance
+			item.CountedByID = &countedByID
+			item.CountedAt = &now
+
+			if err := tx.Model(item).Select("CountedQuantity", "Variance", "CountedByID", "CountedAt").Updates(item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.respondWithError(c, err, "Failed to submit counts")
+		return
+	}
+
+	stockTake, ok := h.loadStockTake(c, id)
+	if !ok {
+		return
+	}
+	utils.OKResponse(c, "Counts submitted successfully", h.stockTakeView(c, stockTake))
+}
+
+// Approve closes a stock take and posts the variance of every counted product to the
+// stock ledger. The variance is measured against the stock at session start, so sales
+// and receipts made while counting are kept.
+func (h *StockTakeHandler) Approve(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	approverID := userID.(uint)
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		stockTake, err := lockOpenStockTake(tx, id)
+		if err != nil {
+			return err
+		}
+		before := stockTakeAuditState(stockTake)
+
+		for _, item := range stockTake.Items {
+			if item.Variance == nil || *item.Variance == 0 {
+				continue
+			}
+			if err := applyStockMovement(tx, &models.StockMovement{
+				ProductID:     item.ProductID,
+				Type:          models.StockMovementStockTake,
+				Quantity:      *item.Variance,
+				ReferenceType: models.AuditEntityStockTake,
+				ReferenceID:   stockTake.ID,
+				CreatedByID:   approverID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		stockTake.Status = models.StockTakeApproved
+		stockTake.ApprovedByID = &approverID
+		stockTake.ApprovedAt = &now
+		if err := tx.Model(stockTake).Select("Status", "ApprovedByID", "ApprovedAt").Updates(stockTake).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityStockTake, stockTake.ID, before, stockTakeAuditState(stockTake))
+	})
+	if err != nil {
+		h.respondWithError(c, err, "Failed to approve stock take")
+		return
+	}
+
+	stockTake, ok := h.loadStockTake(c, id)
+	if !ok {
+		return
+	}
+	utils.OKResponse(c, "Stock take approved successfully", stockTake)
+}
+
+// Cancel closes an open stock take without adjusting stock
+func (h *StockTakeHandler) Cancel(c *gin.Context) {
+	id, ok := parseStockTakeID(c)
+	if !ok {
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		stockTake, err := lockOpenStockTake(tx, id)
+		if err != nil {
+			return err
+		}
+		before := stockTakeAuditState(stockTake)
+
+		stockTake.Status = models.StockTakeCancelled
+		if err := tx.Model(stockTake).Update("status", stockTake.Status).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityStockTake, stockTake.ID, before, stockTakeAuditState(stockTake))
+	})
+	if err != nil {
+		h.respondWithError(c, err, "Failed to cancel stock take")
+		return
+	}
+
+	utils.OKResponse(c, "Stock take cancelled successfully", nil)
+}
+
+// stockTakeAuditState is the audited snapshot of a stock take
+func stockTakeAuditState(stockTake *models.StockTake) map[string]interface{} {
+	counted := 0
+	for _, item := range stockTake.Items {
+		if item.CountedQuantity != nil {
+			counted++
+		}
+	}
+	return map[string]interface{}{
+		"number":         stockTake.Number,
+		"status":         stockTake.Status,
+		"notes":          stockTake.Notes,
+		"approved_by_id": stockTake.ApprovedByID,
+		"products":       len(stockTake.Items),
+		"counted":        counted,
+	}
+}
+
+// lockOpenStockTake loads and locks an open stock take with its items inside tx
+func lockOpenStockTake(tx *gorm.DB, id uint) (*models.StockTake, error) {
+	var stockTake models.StockTake
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stockTake, id).Error; err != nil {
+		return nil, err
+	}
+	if stockTake.Status != models.StockTakeOpen {
+		return nil, &stockTakeError{message: "Stock take is already " + stockTake.Status}
+	}
+	if err := tx.Where("stock_take_id = ?", stockTake.ID).Order("id ASC").Find(&stockTake.Items).Error; err != nil {
+		return nil, err
+	}
+	return &stockTake, nil
+}
+
+func parseStockTakeID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid stock take ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondWithError writes the response for an error returned by a stock take transaction
+func (h *StockTakeHandler) respondWithError(c *gin.Context, err error, message string) {
+	var stErr *stockTakeError
+	if errors.As(err, &stErr) {
+		utils.BadRequestResponse(c, stErr.message)
+		return
+	}
+	if err == gorm.ErrRecordNotFound {
+		utils.NotFoundResponse(c, "Stock take not found")
+		return
+	}
+	utils.InternalServerErrorResponse(c, message)
+}
+
+// loadStockTake loads a stock take with its items and products, writing an error response on failure
+func (h *StockTakeHandler) loadStockTake(c *gin.Context, id uint) (*models.StockTake, bool) {
+	var stockTake models.StockTake
+	if err := h.DB.WithContext(c).Preload("CreatedBy").Preload("ApprovedBy").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Items.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&stockTake, id).Error; err != nil {
+		h.respondWithError(c, err, "Failed to fetch stock take")
+		return nil, false
+	}
+	return &stockTake, true
+}
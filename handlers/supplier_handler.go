@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"strconv"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SupplierHandler struct {
+	DB *gorm.DB
+}
+
+func NewSupplierHandler(db *gorm.DB) *SupplierHandler {
+	return &SupplierHandler{DB: db}
+}
+
+type CreateSupplierRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	ContactName string `json:"contact_name" binding:"max=255"`
+	Phone       string `json:"phone" binding:"max=50"`
+	Email       string `json:"email" binding:"omitempty,email,max=255"`
+	Address     string `json:"address"`
+}
+
+type UpdateSupplierRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	ContactName *string `json:"contact_name" binding:"omitempty,max=255"`
+	Phone       *string `json:"phone" binding:"omitempty,max=50"`
+	Email       *string `json:"email" binding:"omitempty,email,max=255"`
+	Address     *string `json:"address"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// GetAll returns all suppliers with pagination
+func (h *SupplierHandler) GetAll(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var total int64
+	var suppliers []models.Supplier
+
+	if err := h.DB.WithContext(c).Model(&models.Supplier{}).Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count suppliers")
+		return
+	}
+
+	if err := h.DB.WithContext(c).Order("name ASC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&suppliers).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch suppliers")
+		return
+	}
+
+	utils.OKResponse(c, "Suppliers retrieved successfully", utils.PaginatedResponse{
+		Items:      suppliers,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// GetByID returns a supplier by ID
+func (h *SupplierHandler) GetByID(c *gin.Context) {
+	supplier, ok := h.findSupplier(c)
+	if !ok {
+		return
+	}
+
+	utils.OKResponse(c, "Supplier retrieved successfully", supplier)
+}
+
+// Create creates a new supplier
+func (h *SupplierHandler) Create(c *gin.Context) {
+	var req CreateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	supplier := models.Supplier{
+		Name:        req.Name,
+		ContactName: req.ContactName,
+		Phone:       req.Phone,
+		Email:       req.Email,
+		Address:     req.Address,
+		IsActive:    true,
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&supplier).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntitySupplier, supplier.ID, nil, supplier)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create supplier")
+		return
+	}
+
+	utils.CreatedResponse(c, "Supplier created successfully", supplier)
+}
+
+// Update partially updates a supplier
+func (h *SupplierHandler) Update(c *gin.Context) {
+	supplier, ok := h.findSupplier(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := *supplier
+
+	if req.Name != nil {
+		supplier.Name = *req.Name
+	}
+	if req.ContactName != nil {
+		supplier.ContactName = *req.ContactName
+	}
+	if req.Phone != nil {
+		supplier.Phone = *req.Phone
+	}
+	if req.Email != nil {
+		supplier.Email = *req.Email
+	}
+	if req.Address != nil {
+		supplier.Address = *req.Address
+	}
+	if req.IsActive != nil {
+		supplier.IsActive = *req.IsActive
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(supplier).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntitySupplier, supplier.ID, before, supplier)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update supplier")
+		return
+	}
+
+	utils.OKResponse(c, "Supplier updated successfully", supplier)
+}
+
+// Delete soft deletes a supplier without open purchase orders
+func (h *SupplierHandler) Delete(c *gin.Context) {
+	supplier, ok := h.findSupplier(c)
+	if !ok {
+		return
+	}
+
+	var open int64
+	if err := h.DB.WithContext(c).Model(&models.PurchaseOrder{}).
+		Where("supplier_id = ? AND status <> ?", supplier.ID, models.PurchaseOrderReceived).
+		Count(&open).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count purchase orders")
+		return
+	}
+	if open > 0 {
+		utils.BadRequestResponse(c, "Supplier still has open purchase orders")
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(supplier).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntitySupplier, supplier.ID, supplier, nil)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete supplier")
+		return
+	}
+
+	utils.OKResponse(c, "Supplier deleted successfully", nil)
+}
+
+// findSupplier loads the supplier referenced by the :id param, writing an error response on failure
+func (h *SupplierHandler) findSupplier(c *gin.Context) (*models.Supplier, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid supplier ID")
+		return nil, false
+	}
+
+	var supplier models.Supplier
+	if err := h.DB.WithContext(c).First(&supplier, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Supplier not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch supplier")
+		return nil, false
+	}
+
+	return &supplier, true
+}
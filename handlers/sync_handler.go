@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"interview-user/middleware"
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Outcomes of a synced offline order
+const (
+	SyncStatusCreated   = "created"
+	SyncStatusDuplicate = "duplicate"
+	SyncStatusFailed    = "failed"
+)
+
+// syncOverlap is how far before the since token changes are read again. updated_at is
+// stamped when a row is written, not when its transaction commits, so a row written by a
+// transaction still running when the previous token was taken can carry an older time.
+// Records are sent in full, so clients apply repeated ones by ID.
+const syncOverlap = 2 * time.Minute
+
+type SyncHandler struct {
+	DB           *gorm.DB
+	OrderNumbers utils.OrderNumberFormat
+}
+
+func NewSyncHandler(db *gorm.DB, orderNumbers utils.OrderNumberFormat) *SyncHandler {
+	return &SyncHandler{
+		DB:           db,
+		OrderNumbers: orderNumbers,
+	}
+}
+
+type SyncSaleOrdersRequest struct {
+	Orders []SyncSaleOrderRequest `json:"orders" binding:"required,min=1,max=100,dive"`
+}
+
+// SyncSaleOrderRequest is an order created while the terminal was offline.
+// ClientID is generated by the terminal and identifies the order across retries.
+type SyncSaleOrderRequest struct {
+	ClientID        string                       `json:"client_id" binding:"required,uuid"`
+	ClientCreatedAt time.Time                    `json:"client_created_at" binding:"required"`
+	CustomerName    string                       `json:"customer_name" binding:"required"`
+	Notes           string                       `json:"notes"`
+	Items           []CreateSaleOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type SyncSaleOrderResult struct {
+	ClientID    string `json:"client_id"`
+	Status      string `json:"status"`
+	OrderID     uint   `json:"order_id,omitempty"`
+	OrderNumber string `json:"order_number,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// SyncChangesResponse lists the records changed since the previous sync and the IDs of
+// those deleted since then. Products carry the IDs of their modifier groups only; the
+// groups and their modifiers are sent in their own lists.
+type SyncChangesResponse struct {
+	NextToken               string                  `json:"next_token"`
+	Users                   []UserDetailResponse    `json:"users"`
+	DeletedUserIDs          []uint                  `json:"deleted_user_ids"`
+	Categories              []models.Category       `json:"categories"`
+	DeletedCategoryIDs      []uint                  `json:"deleted_category_ids"`
+	Products                []models.Product        `json:"products"`
+	DeletedProductIDs       []uint                  `json:"deleted_product_ids"`
+	Variants                []models.ProductVariant `json:"variants"`
+	DeletedVariantIDs       []uint                  `json:"deleted_variant_ids"`
+	ModifierGroups          []models.ModifierGroup  `json:"modifier_groups"`
+	DeletedModifierGroupIDs []uint                  `json:"deleted_modifier_group_ids"`
+	Modifiers               []models.Modifier       `json:"modifiers"`
+	DeletedModifierIDs      []uint                  `json:"deleted_modifier_ids"`
+}
+
+// SyncSaleOrders stores a batch of orders created offline. Every order is created in its
+// own transaction with a server order number; orders whose client_id was already synced
+// are reported as duplicates, so a terminal can resend the whole batch after a failure.
+func (h *SyncHandler) SyncSaleOrders(c *gin.Context) {
+	var req SyncSaleOrdersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	results := make([]SyncSaleOrderResult, 0, len(req.Orders))
+	for _, reqOrder := range req.Orders {
+		result := SyncSaleOrderResult{ClientID: reqOrder.ClientID}
+
+		if existing, found := h.findSyncedOrder(c, reqOrder.ClientID); found {
+			result.Status = SyncStatusDuplicate
+			result.OrderID = existing.ID
+			result.OrderNumber = existing.OrderNumber
+			results = append(results, result)
+			continue
+		}
+
+		items, totalAmount, message, err := buildSaleOrderItems(h.DB.WithContext(c), reqOrder.Items)
+		if err != nil || message != "" {
+			result.Status = SyncStatusFailed
+			result.Error = message
+			if err != nil {
+				result.Error = "Failed to fetch products"
+			}
+			results = append(results, result)
+			continue
+		}
+		// Synced orders can't carry a supervisor override, so only callers who may
+		// override prices themselves can sell below the catalog price
+		if belowListPrice(items) && !middleware.HasPermission(c, h.DB.WithContext(c), models.PermSaleOrderPriceOverride) {
+			result.Status = SyncStatusFailed
+			result.Error = "Selling below the catalog price needs sale_order.price_override"
+			results = append(results, result)
+			continue
+		}
+		clientID := reqOrder.ClientID
+		clientCreatedAt := reqOrder.ClientCreatedAt
+
+		order := models.SaleOrder{
+			CustomerName:    reqOrder.CustomerName,
+			TotalAmount:     totalAmount,
+			Notes:           reqOrder.Notes,
+			CreatedByID:     userID.(uint),
+			OutletID:        assignedOutletID(c),
+			SaleOrderItems:  items,
+			ClientID:        &clientID,
+			ClientCreatedAt: &clientCreatedAt,
+		}
+
+		err = h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+			return createSaleOrder(tx, c, h.OrderNumbers, &order)
+		})
+		if err != nil {
+			// Another request may have synced the same order concurrently
+			if existing, found := h.findSyncedOrder(c, reqOrder.ClientID); found {
+				result.Status = SyncStatusDuplicate
+				result.OrderID = existing.ID
+				result.OrderNumber = existing.OrderNumber
+			} else {
+				result.Status = SyncStatusFailed
+				result.Error = "Failed to create sale order"
+			}
+			results = append(results, result)
+			continue
+		}
+
+		result.Status = SyncStatusCreated
+		result.OrderID = order.ID
+		result.OrderNumber = order.OrderNumber
+		results = append(results, result)
+	}
+
+	utils.OKResponse(c, "Sale orders synced successfully", results)
+}
+
+// findSyncedOrder looks up an order the caller synced by client ID, including voided orders.
+// Orders of other users are never matched, so a guessed client_id reveals nothing.
+func (h *SyncHandler) findSyncedOrder(c *gin.Context, clientID string) (*models.SaleOrder, bool) {
+	var order models.SaleOrder
+	if err := h.DB.WithContext(c).Unscoped().Where("client_id = ? AND created_by_id = ?", clientID, c.GetUint("user_id")).First(&order).Error; err != nil {
+		return nil, false
+	}
+	return &order, true
+}
+
+// GetChanges returns the records changed after the since token, or everything when it is
+// omitted. The returned next_token is passed as since on the following call. Changes from
+// the syncOverlap window before since are sent again, so clients must upsert by ID.
+func (h *SyncHandler) GetChanges(c *gin.Context) {
+	var since time.Time
+	if token := c.Query("since"); token != "" {
+		nanos, err := strconv.ParseInt(token, 10, 64)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid since token")
+			return
+		}
+		since = time.Unix(0, nanos).Add(-syncOverlap)
+	}
+
+	// Taken before querying so changes made while this request runs are sent next time
+	next := time.Now()
+
+	// Terminals only receive the users of their outlet
+	outlets := callerOutletScope(c, h.DB.WithContext(c))
+
+	var users []models.User
+	if err := outlets.apply(h.DB.WithContext(c), "outlet_id").Where("updated_at > ?", since).Order("updated_at ASC").Find(&users).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch changed users")
+		return
+	}
+
+	response := SyncChangesResponse{
+		NextToken: strconv.FormatInt(next.UnixNano(), 10),
+		Users:     make([]UserDetailResponse, 0, len(users)),
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, toUserDetailResponse(user))
+	}
+
+	var err error
+	if response.DeletedUserIDs, err = deletedSince(outlets.apply(h.DB.WithContext(c), "outlet_id"), &models.User{}, since); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch deleted users")
+		return
+	}
+
+	// The catalog is shared by every outlet of the tenant
+	catalog := []struct {
+		changed interface{}
+		deleted *[]uint
+		model   interface{}
+		query   *gorm.DB
+	}{
+		{&response.Categories, &response.DeletedCategoryIDs, &models.Category{}, h.DB.WithContext(c).Preload("HiddenOutlets")},
+		{&response.Products, &response.DeletedProductIDs, &models.Product{}, h.DB.WithContext(c).Preload("ModifierGroups")},
+		{&response.Variants, &response.DeletedVariantIDs, &models.ProductVariant{}, h.DB.WithContext(c)},
+		{&response.ModifierGroups, &response.DeletedModifierGroupIDs, &models.ModifierGroup{}, h.DB.WithContext(c)},
+		{&response.Modifiers, &response.DeletedModifierIDs, &models.Modifier{}, h.DB.WithContext(c)},
+	}
+	for _, entry := range catalog {
+		if err := entry.query.Where("updated_at > ?", since).Order("updated_at ASC").Find(entry.changed).Error; err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to fetch changed catalog")
+			return
+		}
+		if *entry.deleted, err = deletedSince(h.DB.WithContext(c), entry.model, since); err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to fetch deleted catalog")
+			return
+		}
+	}
+
+	utils.OKResponse(c, "Changes retrieved successfully", response)
+}
+
+// deletedSince returns the IDs of model rows matched by query that were soft deleted after since
+func deletedSince(query *gorm.DB, model interface{}, since time.Time) ([]uint, error) {
+	ids := []uint{}
+	err := query.Unscoped().Model(model).
+		Where("deleted_at IS NOT NULL AND deleted_at > ?", since).
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"regexp"
+
+	"interview-user/database"
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TenantHandler struct {
+	DB             *gorm.DB
+	PasswordPolicy utils.PasswordPolicy
+}
+
+func NewTenantHandler(db *gorm.DB, passwordPolicy utils.PasswordPolicy) *TenantHandler {
+	return &TenantHandler{DB: db, PasswordPolicy: passwordPolicy}
+}
+
+// Tenant slugs are used as subdomains, so they follow DNS label rules
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type CreateTenantRequest struct {
+	Slug          string `json:"slug" binding:"required"`
+	Name          string `json:"name" binding:"required,max=255"`
+	OwnerUsername string `json:"owner_username" binding:"required,min=3,max=100"`
+	OwnerPassword string `json:"owner_password" binding:"required"`
+	OwnerName     string `json:"owner_name" binding:"required,max=255"`
+}
+
+type CreateTenantResponse struct {
+	Tenant models.Tenant      `json:"tenant"`
+	Owner  UserDetailResponse `json:"owner"`
+}
+
+// GetAll returns all tenants with pagination
+func (h *TenantHandler) GetAll(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var total int64
+	var tenants []models.Tenant
+
+	if err := h.DB.Model(&models.Tenant{}).Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count tenants")
+		return
+	}
+
+	if err := h.DB.Order("slug ASC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&tenants).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch tenants")
+		return
+	}
+
+	utils.OKResponse(c, "Tenants retrieved successfully", utils.PaginatedResponse{
+		Items:      tenants,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// Create provisions a new tenant with the system roles and its first owner.
+// The owner must change the password on first login.
+func (h *TenantHandler) Create(c *gin.Context) {
+	var req CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if !tenantSlugPattern.MatchString(req.Slug) {
+		utils.BadRequestResponse(c, "slug must be lowercase letters, digits or dashes and cannot start or end with a dash")
+		return
+	}
+
+	var existing models.Tenant
+	if err := h.DB.Where("slug = ?", req.Slug).First(&existing).Error; err == nil {
+		utils.BadRequestResponse(c, "Tenant slug already exists")
+		return
+	}
+
+	if err := h.PasswordPolicy.Validate(req.OwnerPassword); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	tenant := models.Tenant{
+		Slug:     req.Slug,
+		Name:     req.Name,
+		IsActive: true,
+	}
+	owner := models.User{
+		Username:           req.OwnerUsername,
+		Name:               req.OwnerName,
+		Role:               models.RoleOwner,
+		IsActive:           true,
+		MustChangePassword: true,
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&tenant).Error; err != nil {
+			return err
+		}
+
+		// Everything below belongs to the new tenant
+		tx = tx.WithContext(database.WithTenant(c, tenant.ID))
+
+		if err := database.SeedRoles(tx); err != nil {
+			return err
+		}
+		if err := setUserPassword(tx, &owner, req.OwnerPassword); err != nil {
+			return err
+		}
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityUser, owner.ID, nil, userAuditState(&owner))
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create tenant")
+		return
+	}
+
+	utils.CreatedResponse(c, "Tenant created successfully", CreateTenantResponse{
+		Tenant: tenant,
+		Owner:  toUserDetailResponse(owner),
+	})
+}
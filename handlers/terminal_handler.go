@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"strconv"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type TerminalHandler struct {
+	DB *gorm.DB
+}
+
+func NewTerminalHandler(db *gorm.DB) *TerminalHandler {
+	return &TerminalHandler{DB: db}
+}
+
+type CreateTerminalRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type UpdateTerminalRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=255"`
+	IsActive *bool   `json:"is_active"`
+}
+
+type CreateTerminalResponse struct {
+	Terminal  models.Terminal `json:"terminal"`
+	DeviceKey string          `json:"device_key"`
+}
+
+// callerTerminalID returns the terminal the request came from, if any
+func callerTerminalID(c *gin.Context) *uint {
+	terminalID, exists := c.Get("terminal_id")
+	if !exists {
+		return nil
+	}
+	id := terminalID.(uint)
+	return &id
+}
+
+// GetAll returns all registered terminals with pagination
+func (h *TerminalHandler) GetAll(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var total int64
+	var terminals []models.Terminal
+
+	if err := h.DB.WithContext(c).Model(&models.Terminal{}).Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count terminals")
+		return
+	}
+
+	if err := h.DB.WithContext(c).Order("created_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&terminals).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch terminals")
+		return
+	}
+
+	utils.OKResponse(c, "Terminals retrieved successfully", utils.PaginatedResponse{
+		Items:      terminals,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// Create registers a new terminal and returns its device key.
+// The key is only shown once; only a hash of its secret part is stored.
+func (h *TerminalHandler) Create(c *gin.Context) {
+	var req CreateTerminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	code, err := utils.GenerateRandomHex(8)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to generate terminal credential")
+		return
+	}
+
+	secret, err := utils.GenerateRandomHex(32)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to generate terminal credential")
+		return
+	}
+
+	hashedSecret, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to hash terminal credential")
+		return
+	}
+
+	terminal := models.Terminal{
+		Code:           code,
+		Name:           req.Name,
+		CredentialHash: string(hashedSecret),
+		IsActive:       true,
+		CreatedByID:    userID.(uint),
+	}
+
+	err = h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&terminal).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityTerminal, terminal.ID, nil, terminal)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create terminal")
+		return
+	}
+
+	utils.CreatedResponse(c, "Terminal registered successfully", CreateTerminalResponse{
+		Terminal:  terminal,
+		DeviceKey: code + "." + secret,
+	})
+}
+
+// GetByID returns a terminal by ID
+func (h *TerminalHandler) GetByID(c *gin.Context) {
+	terminal, ok := h.findTerminal(c)
+	if !ok {
+		return
+	}
+
+	utils.OKResponse(c, "Terminal retrieved successfully", terminal)
+}
+
+// Update renames a terminal or enables/disables it. A disabled terminal can no longer
+// PIN-login, and requests made with its credential or its PIN tokens are rejected.
+func (h *TerminalHandler) Update(c *gin.Context) {
+	terminal, ok := h.findTerminal(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateTerminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := *terminal
+
+	if req.Name != nil {
+		terminal.Name = *req.Name
+	}
+	if req.IsActive != nil {
+		terminal.IsActive = *req.IsActive
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(terminal).Select("Name", "IsActive").Updates(terminal).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityTerminal, terminal.ID, before, terminal)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update terminal")
+		return
+	}
+
+	utils.OKResponse(c, "Terminal updated successfully", terminal)
+}
+
+// Delete soft deletes a terminal, revoking its credential for good.
+// Orders keep referencing the terminal that created them.
+func (h *TerminalHandler) Delete(c *gin.Context) {
+	terminal, ok := h.findTerminal(c)
+	if !ok {
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(terminal).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(terminal).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntityTerminal, terminal.ID, terminal, nil)
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete terminal")
+		return
+	}
+
+	utils.OKResponse(c, "Terminal deleted successfully", nil)
+}
+
+// findTerminal loads the terminal referenced by the :id param, writing an error response on failure
+func (h *TerminalHandler) findTerminal(c *gin.Context) (*models.Terminal, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid terminal ID")
+		return nil, false
+	}
+
+	var terminal models.Terminal
+	if err := h.DB.WithContext(c).First(&terminal, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Terminal not found")
+			return nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch terminal")
+		return nil, false
+	}
+
+	return &terminal, true
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TrashHandler struct {
+	DB            *gorm.DB
+	RetentionDays int
+}
+
+func NewTrashHandler(db *gorm.DB, retentionDays int) *TrashHandler {
+	return &TrashHandler{
+		DB:            db,
+		RetentionDays: retentionDays,
+	}
+}
+
+type DeletedSaleOrderResponse struct {
+	models.SaleOrder
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type DeletedUserResponse struct {
+	UserDetailResponse
+	DeletedAt string `json:"deleted_at"`
+}
+
+// userReferences lists every column that points at a user. A user referenced by any
+// of them is kept by Purge so the referencing record does not lose its author.
+var userReferences = []struct {
+	model   interface{}
+	columns []string
+}{
+	{&models.SaleOrder{}, []string{"created_by_id", "approved_by_id"}},
+	{&models.SaleOrderRevision{}, []string{"created_by_id"}},
+	{&models.PurchaseOrder{}, []string{"created_by_id"}},
+	{&models.GoodsReceipt{}, []string{"received_by_id"}},
+	{&models.StockTake{}, []string{"created_by_id", "approved_by_id"}},
+	{&models.StockTakeItem{}, []string{"counted_by_id"}},
+	{&models.StockMovement{}, []string{"created_by_id"}},
+	{&models.Terminal{}, []string{"created_by_id"}},
+}
+
+// userReferenced reports whether any record in userReferences, soft-deleted or not,
+// still points at the user
+func userReferenced(tx *gorm.DB, userID uint) (bool, error) {
+	for _, ref := range userReferences {
+		conditions := make([]string, 0, len(ref.columns))
+		args := make([]interface{}, 0, len(ref.columns))
+		for _, column := range ref.columns {
+			conditions = append(conditions, column+" = ?")
+			args = append(args, userID)
+		}
+
+		var count int64
+		if err := tx.Unscoped().Model(ref.model).
+			Where("("+strings.Join(conditions, " OR ")+")", args...).
+			Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type PurgeResponse struct {
+	Cutoff         string `json:"cutoff"`
+	SaleOrders     int    `json:"sale_orders"`
+	SaleOrderItems int64  `json:"sale_order_items"`
+	Users          int    `json:"users"`
+	SkippedUsers   int    `json:"skipped_users"`
+}
+
+// GetDeletedSaleOrders returns soft-deleted sale orders with pagination
+func (h *TrashHandler) GetDeletedSaleOrders(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var total int64
+	if err := h.DB.WithContext(c).Unscoped().Model(&models.SaleOrder{}).Where("deleted_at IS NOT NULL").Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count deleted sale orders")
+		return
+	}
+
+	var orders []models.SaleOrder
+	if err := h.DB.WithContext(c).Unscoped().
+		Preload("CreatedBy", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("SaleOrderItems", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("SaleOrderItems.Modifiers").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&orders).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch deleted sale orders")
+		return
+	}
+
+	items := make([]DeletedSaleOrderResponse, 0, len(orders))
+	for _, order := range orders {
+		// Only list the items that were deleted together with the order
+		var deletedItems []models.SaleOrderItem
+		for _, item := range order.SaleOrderItems {
+			if item.DeletedAt.Valid && item.DeletedAt.Time.Equal(order.DeletedAt.Time) {
+				deletedItems = append(deletedItems, item)
+			}
+		}
+		order.SaleOrderItems = deletedItems
+
+		items = append(items, DeletedSaleOrderResponse{
+			SaleOrder: order,
+			DeletedAt: order.DeletedAt.Time,
+		})
+	}
+
+	utils.OKResponse(c, "Deleted sale orders retrieved successfully", utils.PaginatedResponse{
+		Items:      items,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// RestoreSaleOrder restores a soft-deleted sale order and the items deleted with it
+func (h *TrashHandler) RestoreSaleOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid sale order ID")
+		return
+	}
+
+	var order models.SaleOrder
+	if err := h.DB.WithContext(c).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&order).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Deleted sale order not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch sale order")
+		return
+	}
+
+	deletedAt := order.DeletedAt.Time
+
+	err = h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.SaleOrderItem{}).
+			Where("sale_order_id = ? AND deleted_at = ?", order.ID, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		// Restoring bumps the version so ETags taken before the delete no longer match
+		if err := updateColumnsVersioned(tx.Unscoped(), &order, order.Version, map[string]interface{}{"deleted_at": nil}); err != nil {
+			return err
+		}
+
+		if err := tx.Preload("SaleOrderItems.Modifiers").First(&order, order.ID).Error; err != nil {
+			return err
+		}
+		// The restored order takes its goods off the shelf again
+		for i := range order.SaleOrderItems {
+			item := &order.SaleOrderItems[i]
+			if err := moveSaleItemStock(tx, c, order.ID, item, -item.Quantity); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, c, models.AuditActionRestore, models.AuditEntitySaleOrder, order.ID, nil, newSaleOrderSnapshot(&order))
+	})
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "Sale order was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to restore sale order")
+		return
+	}
+
+	h.DB.WithContext(c).Preload("CreatedBy").Preload("ApprovedBy").Preload("SaleOrderItems.Modifiers").First(&order, order.ID)
+
+	utils.SetETag(c, order.Version)
+	utils.OKResponse(c, "Sale order restored successfully", order)
+}
+
+// GetDeletedUsers returns soft-deleted users with pagination
+func (h *TrashHandler) GetDeletedUsers(c *gin.Context) {
+	pagination := utils.GetPagination(c)
+
+	var total int64
+	if err := h.DB.WithContext(c).Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL").Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count deleted users")
+		return
+	}
+
+	var users []models.User
+	if err := h.DB.WithContext(c).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&users).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch deleted users")
+		return
+	}
+
+	items := make([]DeletedUserResponse, 0, len(users))
+	for _, user := range users {
+		items = append(items, DeletedUserResponse{
+			UserDetailResponse: toUserDetailResponse(user),
+			DeletedAt:          user.DeletedAt.Time.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	utils.OKResponse(c, "Deleted users retrieved successfully", utils.PaginatedResponse{
+		Items:      items,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
+
+// RestoreUser restores a soft-deleted user
+func (h *TrashHandler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID")
+		return
+	}
+
+	var user models.User
+	if err := h.DB.WithContext(c).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "Deleted user not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch user")
+		return
+	}
+
+	err = h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := updateColumnsVersioned(tx.Unscoped(), &user, user.Version, map[string]interface{}{"deleted_at": nil}); err != nil {
+			return err
+		}
+		user.Version++
+		user.DeletedAt = gorm.DeletedAt{}
+		return recordAudit(tx, c, models.AuditActionRestore, models.AuditEntityUser, user.ID, nil, userAuditState(&user))
+	})
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "User was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to restore user")
+		return
+	}
+
+	utils.SetETag(c, user.Version)
+	utils.OKResponse(c, "User restored successfully", toUserDetailResponse(user))
+}
+
+// Purge permanently deletes records that have been soft-deleted for longer than the
+// retention period. Users still referenced by any record in userReferences are kept.
+func (h *TrashHandler) Purge(c *gin.Context) {
+	cutoff := time.Now().AddDate(0, 0, -h.RetentionDays)
+	result := PurgeResponse{Cutoff: cutoff.Format("2006-01-02T15:04:05Z")}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var orders []models.SaleOrder
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&orders).Error; err != nil {
+			return err
+		}
+
+		for _, order := range orders {
+			if err := tx.Where("sale_order_item_id IN (?)",
+				tx.Unscoped().Model(&models.SaleOrderItem{}).Select("id").Where("sale_order_id = ?", order.ID),
+			).Delete(&models.SaleOrderItemModifier{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("sale_order_id = ?", order.ID).Delete(&models.SaleOrderItem{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("sale_order_id = ?", order.ID).Delete(&models.SaleOrderRevision{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&order).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(tx, c, models.AuditActionPurge, models.AuditEntitySaleOrder, order.ID, nil, nil); err != nil {
+				return err
+			}
+		}
+		result.SaleOrders = len(orders)
+
+		// Items replaced by updates on orders that still exist
+		if err := tx.Where("sale_order_item_id IN (?)",
+			tx.Unscoped().Model(&models.SaleOrderItem{}).Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff),
+		).Delete(&models.SaleOrderItemModifier{}).Error; err != nil {
+			return err
+		}
+		items := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&models.SaleOrderItem{})
+		if items.Error != nil {
+			return items.Error
+		}
+		result.SaleOrderItems = items.RowsAffected
+
+		var users []models.User
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&users).Error; err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			referenced, err := userReferenced(tx, user.ID)
+			if err != nil {
+				return err
+			}
+			if referenced {
+				result.SkippedUsers++
+				continue
+			}
+
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.PasswordHistory{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.IdempotencyKey{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&user).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(tx, c, models.AuditActionPurge, models.AuditEntityUser, user.ID, nil, nil); err != nil {
+				return err
+			}
+			result.Users++
+		}
+
+		return nil
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to purge deleted records")
+		return
+	}
+
+	utils.OKResponse(c, "Deleted records purged successfully", result)
+}
@@ -1,62 +1,393 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
+	"time"
 
+	"interview-user/middleware"
 	"interview-user/models"
 	"interview-user/utils"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserHandler struct {
-	DB *gorm.DB
+	DB             *gorm.DB
+	PasswordPolicy utils.PasswordPolicy
 }
 
-func NewUserHandler(db *gorm.DB) *UserHandler {
-	return &UserHandler{DB: db}
+func NewUserHandler(db *gorm.DB, passwordPolicy utils.PasswordPolicy) *UserHandler {
+	return &UserHandler{
+		DB:             db,
+		PasswordPolicy: passwordPolicy,
+	}
 }
 
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=100"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 	Name     string `json:"name" binding:"required,max=255"`
+	PIN      string `json:"pin" binding:"omitempty,number,min=4,max=6"`
+	OutletID *uint  `json:"outlet_id"`
 }
 
+// UpdateUserRequest updates the given fields; outlet_id 0 removes the outlet assignment
 type UpdateUserRequest struct {
 	Username string `json:"username" binding:"omitempty,min=3,max=100"`
-	Password string `json:"password" binding:"omitempty,min=6"`
+	Password string `json:"password"`
 	Name     string `json:"name" binding:"omitempty,max=255"`
+	PIN      string `json:"pin" binding:"omitempty,number,min=4,max=6"`
 	IsActive *bool  `json:"is_active"`
+	OutletID *uint  `json:"outlet_id"`
+}
+
+type TransferOwnershipRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type UpdateProfileRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type UserDetailResponse struct {
+	ID                 uint        `json:"id"`
+	Username           string      `json:"username"`
+	Name               string      `json:"name"`
+	Role               models.Role `json:"role"`
+	OutletID           *uint       `json:"outlet_id"`
+	IsActive           bool        `json:"is_active"`
+	MustChangePassword bool        `json:"must_change_password"`
+	Version            uint        `json:"version"`
+	CreatedAt          string      `json:"created_at"`
+	UpdatedAt          string      `json:"updated_at"`
+}
+
+func toUserDetailResponse(user models.User) UserDetailResponse {
+	return UserDetailResponse{
+		ID:                 user.ID,
+		Username:           user.Username,
+		Name:               user.Name,
+		Role:               user.Role,
+		OutletID:           user.OutletID,
+		IsActive:           user.IsActive,
+		MustChangePassword: user.MustChangePassword,
+		Version:            user.Version,
+		CreatedAt:          user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:          user.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// managedRole describes a role whose accounts are managed through /users/<role>
+type managedRole struct {
+	Role   models.Role
+	Label  string
+	Plural string
+}
+
+var (
+	cashierRole = managedRole{Role: models.RoleCashier, Label: "Cashier", Plural: "Cashiers"}
+	ownerRole   = managedRole{Role: models.RoleOwner, Label: "Owner", Plural: "Owners"}
+	// anyRole matches users of every role, including custom ones
+	anyRole = managedRole{Label: "User", Plural: "Users"}
+)
+
+// GetByID returns a user of any role by ID
+func (h *UserHandler) GetByID(c *gin.Context) {
+	h.getByIDAndRole(c, anyRole)
 }
 
-type CashierResponse struct {
-	ID        uint        `json:"id"`
-	Username  string      `json:"username"`
-	Name      string      `json:"name"`
-	Role      models.Role `json:"role"`
-	IsActive  bool        `json:"is_active"`
-	CreatedAt string      `json:"created_at"`
-	UpdatedAt string      `json:"updated_at"`
+// Update updates a user of any role. Owners can only be changed with owner.manage.
+func (h *UserHandler) Update(c *gin.Context) {
+	h.updateWithRole(c, anyRole)
+}
+
+// Delete soft deletes a user of any role. Owners can only be deleted with owner.manage.
+func (h *UserHandler) Delete(c *gin.Context) {
+	h.deleteWithRole(c, anyRole)
 }
 
 // GetAllCashiers returns all cashier users with pagination
 func (h *UserHandler) GetAllCashiers(c *gin.Context) {
+	h.getAllByRole(c, cashierRole)
+}
+
+// GetCashierByID returns a cashier user by ID
+func (h *UserHandler) GetCashierByID(c *gin.Context) {
+	h.getByIDAndRole(c, cashierRole)
+}
+
+// CreateCashier creates a new cashier user
+func (h *UserHandler) CreateCashier(c *gin.Context) {
+	h.createWithRole(c, cashierRole)
+}
+
+// UpdateCashier updates a cashier user
+func (h *UserHandler) UpdateCashier(c *gin.Context) {
+	h.updateWithRole(c, cashierRole)
+}
+
+// DeleteCashier soft deletes a cashier user
+func (h *UserHandler) DeleteCashier(c *gin.Context) {
+	h.deleteWithRole(c, cashierRole)
+}
+
+// GetAllOwners returns all owner users with pagination
+func (h *UserHandler) GetAllOwners(c *gin.Context) {
+	h.getAllByRole(c, ownerRole)
+}
+
+// GetOwnerByID returns an owner user by ID
+func (h *UserHandler) GetOwnerByID(c *gin.Context) {
+	h.getByIDAndRole(c, ownerRole)
+}
+
+// CreateOwner creates a new owner user
+func (h *UserHandler) CreateOwner(c *gin.Context) {
+	h.createWithRole(c, ownerRole)
+}
+
+// UpdateOwner updates an owner user, refusing to deactivate the last active owner
+func (h *UserHandler) UpdateOwner(c *gin.Context) {
+	h.updateWithRole(c, ownerRole)
+}
+
+// DeleteOwner soft deletes an owner user, refusing to delete the last active owner
+func (h *UserHandler) DeleteOwner(c *gin.Context) {
+	h.deleteWithRole(c, ownerRole)
+}
+
+// TransferOwnership promotes another active user to owner and demotes the caller to cashier.
+// The caller's token is invalidated so the demoted owner starts a fresh session.
+func (h *UserHandler) TransferOwnership(c *gin.Context) {
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Only an actual owner can hand over ownership, not a role that merely manages owners
+	if role, _ := c.Get("role"); role != models.RoleOwner {
+		utils.ForbiddenResponse(c, "Only owners can transfer ownership")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if req.UserID == userID.(uint) {
+		utils.BadRequestResponse(c, "Cannot transfer ownership to yourself")
+		return
+	}
+
+	var target models.User
+	if err := h.DB.WithContext(c).Where("id = ? AND is_active = ?", req.UserID, true).First(&target).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch user")
+		return
+	}
+
+	if target.Role == models.RoleOwner {
+		utils.BadRequestResponse(c, "User is already an owner")
+		return
+	}
+
+	before := userAuditState(&target)
+	target.Role = models.RoleOwner
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&target).Updates(map[string]interface{}{
+			"role":    models.RoleOwner,
+			"version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityUser, target.ID, before, userAuditState(&target)); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"role":    models.RoleCashier,
+			"version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityUser, userID.(uint),
+			map[string]interface{}{"role": models.RoleOwner},
+			map[string]interface{}{"role": models.RoleCashier})
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to transfer ownership")
+		return
+	}
+
+	if token, exists := c.Get("token"); exists {
+		middleware.BlacklistToken(token.(string))
+	}
+
+	target.Version++
+	utils.SetETag(c, target.Version)
+	utils.OKResponse(c, "Ownership transferred successfully, please log in again", toUserDetailResponse(target))
+}
+
+// GetAll returns all users with pagination, optionally filtered by the role query param
+func (h *UserHandler) GetAll(c *gin.Context) {
 	pagination := utils.GetPagination(c)
 
+	outlets, ok := listOutletScope(c, h.DB.WithContext(c))
+	if !ok {
+		return
+	}
+
+	query := outlets.apply(h.DB.WithContext(c).Model(&models.User{}), "outlet_id")
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
 	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count users")
+		return
+	}
+
 	var users []models.User
+	if err := query.Order("created_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&users).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch users")
+		return
+	}
+
+	items := make([]UserDetailResponse, 0, len(users))
+	for _, u := range users {
+		items = append(items, toUserDetailResponse(u))
+	}
+
+	utils.OKResponse(c, "Users retrieved successfully", utils.PaginatedResponse{
+		Items:      items,
+		TotalItems: total,
+		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+	})
+}
 
-	// Count total cashiers
-	if err := h.DB.Model(&models.User{}).Where("role = ?", models.RoleCashier).Count(&total).Error; err != nil {
+// AssignRole changes a user's role. Granting or revoking the owner role requires
+// the owner.manage permission and the last active owner cannot be demoted.
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID")
+		return
+	}
+
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if uint(id) == c.GetUint("user_id") {
+		utils.ForbiddenResponse(c, "You cannot change your own role")
+		return
+	}
+
+	var user models.User
+	if err := callerOutletScope(c, h.DB.WithContext(c)).apply(h.DB.WithContext(c), "outlet_id").First(&user, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch user")
+		return
+	}
+
+	if !utils.CheckIfMatch(c, user.Version) {
+		return
+	}
+
+	var role models.RoleDefinition
+	if err := h.DB.WithContext(c).Where("name = ?", req.Role).First(&role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.BadRequestResponse(c, "Role not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch role")
+		return
+	}
+
+	if (user.Role == models.RoleOwner || role.Name == models.RoleOwner) &&
+		!middleware.HasPermission(c, h.DB.WithContext(c), models.PermOwnerManage) {
+		utils.ForbiddenResponse(c, "You don't have permission to manage owner accounts")
+		return
+	}
+
+	// Without role.manage a caller can only move users between roles that grant nothing beyond their own
+	if !middleware.CanGrantRole(c, h.DB.WithContext(c), user.Role) || !middleware.CanGrantRole(c, h.DB.WithContext(c), role.Name) {
+		utils.ForbiddenResponse(c, "You cannot assign a role with permissions you don't hold")
+		return
+	}
+
+	before := userAuditState(&user)
+
+	err = h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if user.Role == models.RoleOwner && role.Name != models.RoleOwner && user.IsActive {
+			if err := ensureAnotherActiveOwner(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		if err := updateColumnsVersioned(tx, &user, user.Version, map[string]interface{}{"role": role.Name}); err != nil {
+			return err
+		}
+		user.Role = role.Name
+		user.Version++
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityUser, user.ID, before, userAuditState(&user))
+	})
+	if err == errLastActiveOwner {
+		utils.BadRequestResponse(c, "Cannot change the role of the last active owner")
+		return
+	}
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "User was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to assign role")
+		return
+	}
+
+	utils.SetETag(c, user.Version)
+	utils.OKResponse(c, "Role assigned successfully", toUserDetailResponse(user))
+}
+
+func (h *UserHandler) getAllByRole(c *gin.Context, r managedRole) {
+	pagination := utils.GetPagination(c)
+
+	outlets, ok := listOutletScope(c, h.DB.WithContext(c))
+	if !ok {
+		return
+	}
+
+	var total int64
+	var users []models.User
+
+	// Count total users with the role
+	if err := outlets.apply(h.DB.WithContext(c).Model(&models.User{}), "outlet_id").Where("role = ?", r.Role).Count(&total).Error; err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to count users")
 		return
 	}
 
 	// Get paginated data
-	if err := h.DB.Where("role = ?", models.RoleCashier).
+	if err := outlets.apply(h.DB.WithContext(c), "outlet_id").Where("role = ?", r.Role).
 		Order("created_at DESC").
 		Limit(pagination.Limit).
 		Offset(pagination.GetOffset()).
@@ -66,21 +397,13 @@ func (h *UserHandler) GetAllCashiers(c *gin.Context) {
 	}
 
 	// Convert to response format
-	var cashiers []CashierResponse
+	items := make([]UserDetailResponse, 0, len(users))
 	for _, u := range users {
-		cashiers = append(cashiers, CashierResponse{
-			ID:        u.ID,
-			Username:  u.Username,
-			Name:      u.Name,
-			Role:      u.Role,
-			IsActive:  u.IsActive,
-			CreatedAt: u.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt: u.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-		})
-	}
-
-	utils.OKResponse(c, "Cashiers retrieved successfully", utils.PaginatedResponse{
-		Items:      cashiers,
+		items = append(items, toUserDetailResponse(u))
+	}
+
+	utils.OKResponse(c, r.Plural+" retrieved successfully", utils.PaginatedResponse{
+		Items:      items,
 		TotalItems: total,
 		TotalPages: utils.CalculateTotalPages(total, pagination.Limit),
 		Page:       pagination.Page,
@@ -88,37 +411,54 @@ func (h *UserHandler) GetAllCashiers(c *gin.Context) {
 	})
 }
 
-// GetCashierByID returns a cashier user by ID
-func (h *UserHandler) GetCashierByID(c *gin.Context) {
+// findByRole loads the user referenced by the :id param, writing an error response on failure.
+// With anyRole, owners are only returned to callers holding owner.manage.
+func (h *UserHandler) findByRole(c *gin.Context, r managedRole) (*models.User, bool) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		utils.BadRequestResponse(c, "Invalid user ID")
-		return
+		return nil, false
+	}
+
+	query := callerOutletScope(c, h.DB.WithContext(c)).apply(h.DB.WithContext(c), "outlet_id").Where("id = ?", id)
+	if r.Role != "" {
+		query = query.Where("role = ?", r.Role)
 	}
 
 	var user models.User
-	if err := h.DB.Where("id = ? AND role = ?", id, models.RoleCashier).First(&user).Error; err != nil {
+	if err := query.First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Cashier not found")
-			return
+			utils.NotFoundResponse(c, r.Label+" not found")
+			return nil, false
 		}
 		utils.InternalServerErrorResponse(c, "Failed to fetch user")
+		return nil, false
+	}
+
+	if r.Role == "" && user.Role == models.RoleOwner &&
+		!middleware.HasPermission(c, h.DB.WithContext(c), models.PermOwnerManage) {
+		utils.ForbiddenResponse(c, "You don't have permission to manage owner accounts")
+		return nil, false
+	}
+	if r.Role == "" && !middleware.CanGrantRole(c, h.DB.WithContext(c), user.Role) {
+		utils.ForbiddenResponse(c, "You cannot manage a user whose role has permissions you don't hold")
+		return nil, false
+	}
+
+	return &user, true
+}
+
+func (h *UserHandler) getByIDAndRole(c *gin.Context, r managedRole) {
+	user, ok := h.findByRole(c, r)
+	if !ok {
 		return
 	}
 
-	utils.OKResponse(c, "Cashier retrieved successfully", CashierResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-	})
+	utils.SetETag(c, user.Version)
+	utils.OKResponse(c, r.Label+" retrieved successfully", toUserDetailResponse(*user))
 }
 
-// CreateCashier creates a new cashier user
-func (h *UserHandler) CreateCashier(c *gin.Context) {
+func (h *UserHandler) createWithRole(c *gin.Context, r managedRole) {
 	var req CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ValidationErrorResponse(c, err)
@@ -127,57 +467,66 @@ func (h *UserHandler) CreateCashier(c *gin.Context) {
 
 	// Check if username already exists
 	var existingUser models.User
-	if err := h.DB.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
+	if err := h.DB.WithContext(c).Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
 		utils.BadRequestResponse(c, "Username already exists")
 		return
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to hash password")
+	if err := h.PasswordPolicy.Validate(req.Password); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	// New accounts join the creator's outlet unless another one is picked
+	outletID, ok := resolveOutletAssignment(c, h.DB.WithContext(c), req.OutletID, assignedOutletID(c))
+	if !ok {
 		return
 	}
 
+	// Accounts created by the owner must pick their own password on first login
 	user := models.User{
-		Username: req.Username,
-		Password: string(hashedPassword),
-		Name:     req.Name,
-		Role:     models.RoleCashier,
-		IsActive: true,
+		Username:           req.Username,
+		Name:               req.Name,
+		Role:               r.Role,
+		IsActive:           true,
+		MustChangePassword: true,
+		OutletID:           outletID,
 	}
 
-	if err := h.DB.Create(&user).Error; err != nil {
+	if req.PIN != "" {
+		hashedPIN, err := bcrypt.GenerateFromPassword([]byte(req.PIN), bcrypt.DefaultCost)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to hash PIN")
+			return
+		}
+		user.PIN = string(hashedPIN)
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := setUserPassword(tx, &user, req.Password); err != nil {
+			return err
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionCreate, models.AuditEntityUser, user.ID, nil, userAuditState(&user))
+	})
+	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create user")
 		return
 	}
 
-	utils.CreatedResponse(c, "Cashier created successfully", CashierResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-	})
+	utils.SetETag(c, user.Version)
+	utils.CreatedResponse(c, r.Label+" created successfully", toUserDetailResponse(user))
 }
 
-// UpdateCashier updates a cashier user
-func (h *UserHandler) UpdateCashier(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		utils.BadRequestResponse(c, "Invalid user ID")
+func (h *UserHandler) updateWithRole(c *gin.Context, r managedRole) {
+	user, ok := h.findByRole(c, r)
+	if !ok {
 		return
 	}
 
-	var user models.User
-	if err := h.DB.Where("id = ? AND role = ?", id, models.RoleCashier).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Cashier not found")
-			return
-		}
-		utils.InternalServerErrorResponse(c, "Failed to fetch user")
+	if !utils.CheckIfMatch(c, user.Version) {
 		return
 	}
 
@@ -187,11 +536,13 @@ func (h *UserHandler) UpdateCashier(c *gin.Context) {
 		return
 	}
 
+	before := userAuditState(user)
+
 	// Update fields if provided
 	if req.Username != "" {
 		// Check if username already exists (for another user)
 		var existingUser models.User
-		if err := h.DB.Where("username = ? AND id != ?", req.Username, id).First(&existingUser).Error; err == nil {
+		if err := h.DB.WithContext(c).Where("username = ? AND id != ?", req.Username, user.ID).First(&existingUser).Error; err == nil {
 			utils.BadRequestResponse(c, "Username already exists")
 			return
 		}
@@ -199,60 +550,196 @@ func (h *UserHandler) UpdateCashier(c *gin.Context) {
 	}
 
 	if req.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		message, err := validateNewPassword(h.DB.WithContext(c), h.PasswordPolicy, user, req.Password)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to validate password")
+			return
+		}
+		if message != "" {
+			utils.BadRequestResponse(c, message)
+			return
+		}
+
+		// A password reset by the owner has to be replaced by the user
+		user.MustChangePassword = true
+	}
+
+	if req.PIN != "" {
+		hashedPIN, err := bcrypt.GenerateFromPassword([]byte(req.PIN), bcrypt.DefaultCost)
 		if err != nil {
-			utils.InternalServerErrorResponse(c, "Failed to hash password")
+			utils.InternalServerErrorResponse(c, "Failed to hash PIN")
 			return
 		}
-		user.Password = string(hashedPassword)
+		user.PIN = string(hashedPIN)
 	}
 
 	if req.Name != "" {
 		user.Name = req.Name
 	}
 
+	outletID, ok := resolveOutletAssignment(c, h.DB.WithContext(c), req.OutletID, user.OutletID)
+	if !ok {
+		return
+	}
+	user.OutletID = outletID
+
+	deactivating := req.IsActive != nil && !*req.IsActive && user.IsActive
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
 
-	if err := h.DB.Save(&user).Error; err != nil {
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if deactivating && user.Role == models.RoleOwner {
+			if err := ensureAnotherActiveOwner(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		// The old hash goes to the history in the same transaction, so a failed update
+		// doesn't keep a password that was never set from being reused
+		if req.Password != "" {
+			if err := setUserPassword(tx, user, req.Password); err != nil {
+				return err
+			}
+		}
+		if err := updateVersioned(tx, user, &user.Version); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityUser, user.ID, before, userAuditState(user))
+	})
+	if err == errLastActiveOwner {
+		utils.BadRequestResponse(c, "Cannot deactivate the last active owner")
+		return
+	}
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "User was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to update user")
 		return
 	}
 
-	utils.OKResponse(c, "Cashier updated successfully", CashierResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-	})
+	utils.SetETag(c, user.Version)
+	utils.OKResponse(c, r.Label+" updated successfully", toUserDetailResponse(*user))
 }
 
-// DeleteCashier soft deletes a cashier user
-func (h *UserHandler) DeleteCashier(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+func (h *UserHandler) deleteWithRole(c *gin.Context, r managedRole) {
+	user, ok := h.findByRole(c, r)
+	if !ok {
+		return
+	}
+
+	if !utils.CheckIfMatch(c, user.Version) {
+		return
+	}
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if user.Role == models.RoleOwner && user.IsActive {
+			if err := ensureAnotherActiveOwner(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		if err := updateColumnsVersioned(tx, user, user.Version, map[string]interface{}{"deleted_at": time.Now()}); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionDelete, models.AuditEntityUser, user.ID, userAuditState(user), nil)
+	})
+	if err == errLastActiveOwner {
+		utils.BadRequestResponse(c, "Cannot delete the last active owner")
+		return
+	}
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "User was modified by another request, reload and try again")
+		return
+	}
 	if err != nil {
-		utils.BadRequestResponse(c, "Invalid user ID")
+		utils.InternalServerErrorResponse(c, "Failed to delete user")
 		return
 	}
 
+	utils.OKResponse(c, r.Label+" deleted successfully", nil)
+}
+
+var errLastActiveOwner = errors.New("last active owner")
+
+// ensureAnotherActiveOwner returns errLastActiveOwner unless an active owner other than userID exists.
+// Active owners are locked so concurrent requests cannot remove each other.
+func ensureAnotherActiveOwner(tx *gorm.DB, userID uint) error {
+	var owners []models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND is_active = ?", models.RoleOwner, true).
+		Find(&owners).Error; err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		if owner.ID != userID {
+			return nil
+		}
+	}
+	return errLastActiveOwner
+}
+
+// GetMe returns the currently authenticated user
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
 	var user models.User
-	if err := h.DB.Where("id = ? AND role = ?", id, models.RoleCashier).First(&user).Error; err != nil {
+	if err := h.DB.WithContext(c).First(&user, userID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Cashier not found")
+			utils.NotFoundResponse(c, "User not found")
 			return
 		}
 		utils.InternalServerErrorResponse(c, "Failed to fetch user")
 		return
 	}
 
-	if err := h.DB.Delete(&user).Error; err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to delete user")
+	utils.SetETag(c, user.Version)
+	utils.OKResponse(c, "Profile retrieved successfully", toUserDetailResponse(user))
+}
+
+// UpdateMe updates the currently authenticated user's profile
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var user models.User
+	if err := h.DB.WithContext(c).First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch user")
+		return
+	}
+
+	if !utils.CheckIfMatch(c, user.Version) {
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	before := userAuditState(&user)
+	user.Name = req.Name
+
+	err := h.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := updateVersioned(tx, &user, &user.Version); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, models.AuditActionUpdate, models.AuditEntityUser, user.ID, before, userAuditState(&user))
+	})
+	if err == errVersionConflict {
+		utils.ConflictResponse(c, "Profile was modified by another request, reload and try again")
+		return
+	}
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update profile")
 		return
 	}
 
-	utils.OKResponse(c, "Cashier deleted successfully", nil)
+	utils.SetETag(c, user.Version)
+	utils.OKResponse(c, "Profile updated successfully", toUserDetailResponse(user))
 }
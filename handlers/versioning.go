@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errVersionConflict = errors.New("version conflict")
+
+// updateVersioned writes every column of model, which must be a pointer to a loaded record,
+// only if its stored version still equals *version. On success the version is incremented;
+// errVersionConflict is returned when another request changed the row in the meantime.
+func updateVersioned(tx *gorm.DB, model interface{}, version *uint) error {
+	current := *version
+	*version = current + 1
+
+	result := tx.Model(model).
+		Where("version = ?", current).
+		Select("*").
+		Omit(clause.Associations, "CreatedAt").
+		Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = errVersionConflict
+	}
+	if result.Error != nil {
+		*version = current
+	}
+	return result.Error
+}
+
+// updateColumnsVersioned updates the given columns and increments the version only if the
+// stored version still equals version.
+func updateColumnsVersioned(tx *gorm.DB, model interface{}, version uint, columns map[string]interface{}) error {
+	columns["version"] = gorm.Expr("version + 1")
+
+	result := tx.Model(model).Where("version = ?", version).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errVersionConflict
+	}
+	return nil
+}
@@ -39,7 +39,7 @@ func main() {
 	}
 
 	// Initialize JWT service
-	jwtService := utils.NewJWTService(cfg.JWTSecret, cfg.JWTExpiry)
+	jwtService := utils.NewJWTService(cfg.JWTSecret, cfg.JWTExpiry, cfg.PINExpiry)
 
 	//run in release mode
 	ginMode := os.Getenv("GIN_MODE")
@@ -51,11 +51,15 @@ func main() {
 	// Setup Gin router
 	r := gin.Default()
 
+	// Lets handlers pass the gin context to GORM so queries pick up the request's tenant
+	r.ContextWithFallback = true
+
 	// CORS middleware
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Terminal-Key, X-Override-Username, X-Override-Password, X-Override-PIN, If-Match, Idempotency-Key, X-Tenant, X-Platform-Key")
+		c.Header("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -64,7 +68,7 @@ func main() {
 	})
 
 	// Setup routes
-	routes.SetupRoutes(r, db, jwtService)
+	routes.SetupRoutes(r, db, jwtService, cfg)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.ServerPort)
@@ -8,6 +8,7 @@ import (
 	"interview-user/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // TokenBlacklist stores invalidated tokens
@@ -16,8 +17,18 @@ var TokenBlacklist = struct {
 	tokens map[string]bool
 }{tokens: make(map[string]bool)}
 
-// AuthMiddleware validates JWT token
-func AuthMiddleware(jwtService *utils.JWTService) gin.HandlerFunc {
+// passwordChangeAllowedRoutes are the only routes reachable while a password change is pending
+var passwordChangeAllowedRoutes = map[string]bool{
+	"POST /auth/change-password": true,
+	"POST /auth/logout":          true,
+	"GET /me":                    true,
+	"POST /me/password":          true,
+}
+
+// AuthMiddleware validates the JWT token and checks the account it was issued for is still
+// active. The role, pending password change and assigned outlet are read from the database,
+// so a role change, password reset or outlet move applies to tokens issued before it right away.
+func AuthMiddleware(jwtService *utils.JWTService, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -52,11 +63,60 @@ func AuthMiddleware(jwtService *utils.JWTService) gin.HandlerFunc {
 			return
 		}
 
+		// A token is only valid for the tenant it was issued by
+		if tenantID, exists := c.Get("tenant_id"); exists {
+			if tenantID.(uint) != claims.TenantID {
+				utils.UnauthorizedResponse(c, "Token does not belong to this tenant")
+				c.Abort()
+				return
+			}
+		} else {
+			// Requests without a tenant slug were not checked by TenantMiddleware
+			active, err := tenantActive(db, claims.TenantID)
+			if err != nil {
+				utils.InternalServerErrorResponse(c, "Failed to resolve tenant")
+				c.Abort()
+				return
+			}
+			if !active {
+				utils.UnauthorizedResponse(c, "Tenant is no longer active")
+				c.Abort()
+				return
+			}
+			setTenant(c, claims.TenantID)
+		}
+
+		var user models.User
+		if err := db.WithContext(c).Select("id", "role", "is_active", "must_change_password", "outlet_id").
+			Where("id = ? AND is_active = ?", claims.UserID, true).
+			First(&user).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				utils.UnauthorizedResponse(c, "Account is no longer active")
+				c.Abort()
+				return
+			}
+			utils.InternalServerErrorResponse(c, "Failed to verify account")
+			c.Abort()
+			return
+		}
+
+		if user.MustChangePassword && !passwordChangeAllowedRoutes[c.Request.Method+" "+c.FullPath()] {
+			utils.ForbiddenResponse(c, "Password change required before accessing this resource")
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		c.Set("role", user.Role)
 		c.Set("token", tokenString)
+		if user.OutletID != nil {
+			c.Set("outlet_id", *user.OutletID)
+		}
+		if claims.TerminalID != 0 {
+			c.Set("terminal_id", claims.TerminalID)
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyHeader lets clients safely retry a mutating request
+const IdempotencyHeader = "Idempotency-Key"
+
+// secretResponseRoutes return a secret in their body, a fresh JWT or a terminal's
+// device key, which must not be stored for replay, so they are handled without
+// idempotency keys. Every route whose response carries a credential belongs here.
+var secretResponseRoutes = map[string]bool{
+	http.MethodPost + " /auth/change-password": true,
+	http.MethodPost + " /me/password":          true,
+	http.MethodPost + " /terminals":            true,
+}
+
+// idempotencyWriter keeps a copy of the response body so it can be replayed
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware replays the stored response when a mutating request is retried with
+// the same Idempotency-Key. Keys are scoped to the authenticated user and kept for ttl.
+// Reusing a key with a different request is rejected with 422, and a retry that arrives while
+// the original is still running gets 409. Server errors and panics are not stored so they can
+// be retried. Routes whose response carries a secret ignore the header.
+func IdempotencyMiddleware(db *gorm.DB, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions ||
+			secretResponseRoutes[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		if len(key) > 255 {
+			utils.BadRequestResponse(c, "Idempotency-Key must be at most 255 characters")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			utils.BadRequestResponse(c, "Failed to read request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		userID := c.GetUint("user_id")
+
+		// Forget keys that outlived the retention period
+		if err := db.Where("user_id = ? AND created_at < ?", userID, time.Now().Add(-ttl)).
+			Delete(&models.IdempotencyKey{}).Error; err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to check idempotency key")
+			c.Abort()
+			return
+		}
+
+		record := models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash,
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			utils.InternalServerErrorResponse(c, "Failed to check idempotency key")
+			c.Abort()
+			return
+		}
+
+		if result.RowsAffected == 0 {
+			var existing models.IdempotencyKey
+			if err := db.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+				utils.InternalServerErrorResponse(c, "Failed to check idempotency key")
+				c.Abort()
+				return
+			}
+
+			switch {
+			case existing.RequestHash != requestHash:
+				utils.ErrorResponse(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+			case existing.StatusCode == 0:
+				utils.ConflictResponse(c, "A request with this Idempotency-Key is still being processed")
+			default:
+				if existing.ETag != "" {
+					c.Header("ETag", existing.ETag)
+				}
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.StatusCode, "application/json; charset=utf-8", existing.ResponseBody)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// A panicking handler must not leave the key stuck in the processing state
+		defer func() {
+			if r := recover(); r != nil {
+				db.Delete(&record)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			db.Delete(&record)
+			return
+		}
+
+		db.Model(&record).Updates(map[string]interface{}{
+			"status_code":   status,
+			"response_body": writer.body.Bytes(),
+			"etag":          writer.Header().Get("ETag"),
+		})
+	}
+}
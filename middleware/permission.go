@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"sync"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// tenantRole identifies a role; every tenant has its own roles
+type tenantRole struct {
+	tenantID uint
+	role     models.Role
+}
+
+// permissionCache stores the resolved permissions of each role
+var permissionCache = struct {
+	sync.RWMutex
+	roles map[tenantRole]map[models.Permission]bool
+}{roles: make(map[tenantRole]map[models.Permission]bool)}
+
+// InvalidatePermissionCache must be called whenever role permissions change
+func InvalidatePermissionCache() {
+	permissionCache.Lock()
+	permissionCache.roles = make(map[tenantRole]map[models.Permission]bool)
+	permissionCache.Unlock()
+}
+
+// RolePermissions returns the set of permissions granted to role in the tenant.
+// The owner role always holds every permission so it can never lock itself out.
+func RolePermissions(db *gorm.DB, tenantID uint, role models.Role) (map[models.Permission]bool, error) {
+	key := tenantRole{tenantID: tenantID, role: role}
+
+	permissionCache.RLock()
+	permissions, ok := permissionCache.roles[key]
+	permissionCache.RUnlock()
+	if ok {
+		return permissions, nil
+	}
+
+	permissions = make(map[models.Permission]bool)
+	if role == models.RoleOwner {
+		for _, p := range models.AllPermissions {
+			permissions[p] = true
+		}
+	} else {
+		var rows []models.RolePermission
+		if err := db.Joins("JOIN roles ON roles.id = role_permissions.role_id").
+			Where("roles.tenant_id = ? AND roles.name = ?", tenantID, role).
+			Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			permissions[row.Permission] = true
+		}
+	}
+
+	permissionCache.Lock()
+	permissionCache.roles[key] = permissions
+	permissionCache.Unlock()
+
+	return permissions, nil
+}
+
+// HasPermission reports whether the authenticated user's role grants permission
+func HasPermission(c *gin.Context, db *gorm.DB, permission models.Permission) bool {
+	roleValue, exists := c.Get("role")
+	if !exists {
+		return false
+	}
+
+	permissions, err := RolePermissions(db, c.GetUint("tenant_id"), roleValue.(models.Role))
+	if err != nil {
+		return false
+	}
+	return permissions[permission]
+}
+
+// CanGrantRole reports whether the caller may hand out the given role: either
+// they hold role.manage or their own role already grants every permission of it
+func CanGrantRole(c *gin.Context, db *gorm.DB, role models.Role) bool {
+	if HasPermission(c, db, models.PermRoleManage) {
+		return true
+	}
+
+	granted, err := RolePermissions(db, c.GetUint("tenant_id"), role)
+	if err != nil {
+		return false
+	}
+	for permission := range granted {
+		if !HasPermission(c, db, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// RequirePermission checks that the user's role grants all of the given permissions
+func RequirePermission(db *gorm.DB, required ...models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleValue, exists := c.Get("role")
+		if !exists {
+			utils.UnauthorizedResponse(c, "User role not found")
+			c.Abort()
+			return
+		}
+
+		permissions, err := RolePermissions(db, c.GetUint("tenant_id"), roleValue.(models.Role))
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to resolve permissions")
+			c.Abort()
+			return
+		}
+
+		for _, permission := range required {
+			if !permissions[permission] {
+				utils.ForbiddenResponse(c, "You don't have permission to access this resource")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
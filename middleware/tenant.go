@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"interview-user/database"
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TenantHeader selects the tenant by slug when the subdomain cannot be used
+const TenantHeader = "X-Tenant"
+
+// PlatformKeyHeader carries the platform admin key used to manage tenants
+const PlatformKeyHeader = "X-Platform-Key"
+
+// tenantActiveTTL is how long a tenant found active is trusted before it is checked again,
+// so deactivating a tenant locks out its existing tokens within this time
+const tenantActiveTTL = time.Minute
+
+// activeTenants caches when each tenant was last found active
+var activeTenants = struct {
+	sync.RWMutex
+	checked map[uint]time.Time
+}{checked: make(map[uint]time.Time)}
+
+// tenantActive reports whether the tenant exists and is active
+func tenantActive(db *gorm.DB, tenantID uint) (bool, error) {
+	activeTenants.RLock()
+	checked, ok := activeTenants.checked[tenantID]
+	activeTenants.RUnlock()
+	if ok && time.Since(checked) < tenantActiveTTL {
+		return true, nil
+	}
+
+	var count int64
+	if err := db.Model(&models.Tenant{}).Where("id = ? AND is_active = ?", tenantID, true).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	activeTenants.Lock()
+	if count > 0 {
+		activeTenants.checked[tenantID] = time.Now()
+	} else {
+		delete(activeTenants.checked, tenantID)
+	}
+	activeTenants.Unlock()
+	return count > 0, nil
+}
+
+// setTenant stores the tenant in the gin context and in the request context,
+// which scopes the queries of handlers that pass the request context to GORM
+func setTenant(c *gin.Context, tenantID uint) {
+	c.Set("tenant_id", tenantID)
+	c.Request = c.Request.WithContext(database.WithTenant(c.Request.Context(), tenantID))
+}
+
+// TenantMiddleware resolves the tenant of the request. In single-tenant mode every request
+// belongs to the default tenant. In multi-tenant mode the tenant is taken from the
+// X-Tenant header or the subdomain of baseDomain; when neither is given AuthMiddleware
+// takes it from the token.
+func TenantMiddleware(db *gorm.DB, multiTenant bool, baseDomain string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := models.DefaultTenantSlug
+		if multiTenant {
+			slug = c.GetHeader(TenantHeader)
+			if slug == "" {
+				slug = subdomainTenant(c.Request.Host, baseDomain)
+			}
+			if slug == "" {
+				c.Next()
+				return
+			}
+		}
+
+		var tenant models.Tenant
+		if err := db.Where("slug = ? AND is_active = ?", slug, true).First(&tenant).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				utils.NotFoundResponse(c, "Tenant not found")
+				c.Abort()
+				return
+			}
+			utils.InternalServerErrorResponse(c, "Failed to resolve tenant")
+			c.Abort()
+			return
+		}
+
+		setTenant(c, tenant.ID)
+		c.Next()
+	}
+}
+
+// subdomainTenant returns the slug of host "<slug>.<baseDomain>"
+func subdomainTenant(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	slug, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !found || strings.Contains(slug, ".") {
+		return ""
+	}
+	return slug
+}
+
+// RequireTenant rejects requests whose tenant could not be resolved, such as a login
+// without the X-Tenant header in multi-tenant mode
+func RequireTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("tenant_id"); !exists {
+			utils.BadRequestResponse(c, "Tenant is required, send the X-Tenant header or use the tenant subdomain")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// PlatformAuthMiddleware protects tenant management with the platform admin key.
+// The endpoints are disabled when no key is configured.
+func PlatformAuthMiddleware(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key == "" {
+			utils.NotFoundResponse(c, "Not found")
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader(PlatformKeyHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+			utils.UnauthorizedResponse(c, "Invalid platform key")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
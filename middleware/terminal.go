@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"interview-user/models"
+	"interview-user/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// TerminalHeader carries the device credential issued when a terminal is registered
+const TerminalHeader = "X-Terminal-Key"
+
+// authenticateTerminal validates a "<code>.<secret>" device credential against the active terminals
+func authenticateTerminal(c *gin.Context, db *gorm.DB, key string) (*models.Terminal, bool) {
+	code, secret, found := strings.Cut(key, ".")
+	if !found || code == "" || secret == "" {
+		return nil, false
+	}
+
+	var terminal models.Terminal
+	if err := db.WithContext(c).Where("code = ? AND is_active = ?", code, true).First(&terminal).Error; err != nil {
+		return nil, false
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(terminal.CredentialHash), []byte(secret)); err != nil {
+		return nil, false
+	}
+
+	db.WithContext(c).Model(&terminal).UpdateColumn("last_seen_at", time.Now())
+	return &terminal, true
+}
+
+// TerminalAuthMiddleware validates the terminal credential and stores the terminal in context
+func TerminalAuthMiddleware(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(TerminalHeader)
+		if key == "" {
+			utils.UnauthorizedResponse(c, "Terminal credential is required")
+			c.Abort()
+			return
+		}
+
+		terminal, ok := authenticateTerminal(c, db, key)
+		if !ok {
+			utils.UnauthorizedResponse(c, "Invalid terminal credential")
+			c.Abort()
+			return
+		}
+
+		c.Set("terminal_id", terminal.ID)
+		c.Next()
+	}
+}
+
+// TerminalIdentityMiddleware resolves the terminal of an authenticated request. PIN tokens
+// carry the terminal they were issued on; other requests may send X-Terminal-Key. Requests
+// from a disabled terminal are rejected, and when requireForCashier is set cashiers must
+// send one of the two. Must run after AuthMiddleware.
+func TerminalIdentityMiddleware(db *gorm.DB, requireForCashier bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if terminalID, exists := c.Get("terminal_id"); exists {
+			var terminal models.Terminal
+			if err := db.WithContext(c).Where("id = ? AND is_active = ?", terminalID, true).First(&terminal).Error; err != nil {
+				utils.UnauthorizedResponse(c, "Terminal has been disabled")
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if key := c.GetHeader(TerminalHeader); key != "" {
+			terminal, ok := authenticateTerminal(c, db, key)
+			if !ok {
+				utils.UnauthorizedResponse(c, "Invalid terminal credential")
+				c.Abort()
+				return
+			}
+			c.Set("terminal_id", terminal.ID)
+			c.Next()
+			return
+		}
+
+		if role, _ := c.Get("role"); requireForCashier && role == models.RoleCashier {
+			utils.ForbiddenResponse(c, "Cashier requests must come from a registered terminal")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
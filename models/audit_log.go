@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// Audited entity types
+const (
+	AuditEntitySaleOrder = "sale_order"
+	AuditEntityUser      = "user"
+	AuditEntityRole      = "role"
+	AuditEntityTerminal  = "terminal"
+	AuditEntityOutlet    = "outlet"
+	AuditEntityProduct   = "product"
+	AuditEntityCategory  = "category"
+	AuditEntityModifier  = "modifier_group"
+	AuditEntitySupplier  = "supplier"
+	AuditEntityPurchase  = "purchase_order"
+	AuditEntityStockTake = "stock_take"
+)
+
+// AuditLog records who changed what. Rows are append-only: the migration installs
+// a trigger that rejects UPDATE and DELETE on the table.
+type AuditLog struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	TenantID      uint            `gorm:"not null;index" json:"-"`
+	ActorID       *uint           `gorm:"index" json:"actor_id"`
+	ActorUsername string          `gorm:"size:100" json:"actor_username"`
+	Action        string          `gorm:"not null;size:50;index" json:"action"`
+	EntityType    string          `gorm:"not null;size:50;index:idx_audit_logs_entity" json:"entity_type"`
+	EntityID      uint            `gorm:"not null;index:idx_audit_logs_entity" json:"entity_id"`
+	Changes       json.RawMessage `gorm:"type:jsonb" json:"changes"`
+	IPAddress     string          `gorm:"size:45" json:"ip_address"`
+	CreatedAt     time.Time       `gorm:"index" json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
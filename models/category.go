@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Category groups products for the menu and sales reports. Categories nest through
+// ParentID (e.g. Drinks > Coffee > Hot) and are ordered by SortOrder within their parent.
+// A category hidden at an outlet is left out of that outlet's menu together with its
+// subcategories.
+type Category struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	TenantID      uint           `gorm:"not null;index" json:"-"`
+	ParentID      *uint          `gorm:"index" json:"parent_id"`
+	Name          string         `gorm:"not null;size:255" json:"name"`
+	SortOrder     int            `gorm:"not null;default:0" json:"sort_order"`
+	HiddenOutlets []Outlet       `gorm:"many2many:category_hidden_outlets" json:"hidden_outlets"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IdempotencyKey stores the outcome of a mutating request sent with an Idempotency-Key
+// header so a retried request returns the original response. A zero StatusCode marks
+// a request that is still being processed.
+type IdempotencyKey struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_idempotency_user_key" json:"user_id"`
+	Key          string    `gorm:"not null;size:255;uniqueIndex:idx_idempotency_user_key" json:"key"`
+	RequestHash  string    `gorm:"not null;size:64" json:"request_hash"`
+	StatusCode   int       `gorm:"not null;default:0" json:"status_code"`
+	ResponseBody []byte    `json:"-"`
+	ETag         string    `gorm:"column:etag;size:100" json:"-"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
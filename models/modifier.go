@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModifierGroup is a set of add-ons or options such as "Sugar level" that can be
+// attached to products. A sale order item must pick between MinSelections and
+// MaxSelections modifiers of every group of its product; MaxSelections 0 means no limit.
+type ModifierGroup struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	TenantID      uint           `gorm:"not null;index" json:"-"`
+	Name          string         `gorm:"not null;size:255" json:"name"`
+	MinSelections int            `gorm:"not null;default:0" json:"min_selections"`
+	MaxSelections int            `gorm:"not null;default:0" json:"max_selections"`
+	Modifiers     []Modifier     `gorm:"foreignKey:ModifierGroupID" json:"modifiers,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (ModifierGroup) TableName() string {
+	return "modifier_groups"
+}
+
+// Required reports whether at least one modifier of the group must be picked
+func (g ModifierGroup) Required() bool {
+	return g.MinSelections > 0
+}
+
+// Modifier is one option of a modifier group; PriceDelta is added to the unit price
+type Modifier struct {
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	TenantID        uint           `gorm:"not null;index" json:"-"`
+	ModifierGroupID uint           `gorm:"not null;index" json:"modifier_group_id"`
+	Name            string         `gorm:"not null;size:255" json:"name"`
+	PriceDelta      float64        `gorm:"not null;default:0" json:"price_delta"`
+	IsActive        bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Modifier) TableName() string {
+	return "modifiers"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// OrderSequence holds the last order number issued for a scope and reset period.
+// Rows are locked while an order is created so numbers are issued without gaps.
+type OrderSequence struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `gorm:"not null;uniqueIndex:idx_order_sequences_tenant_scope_period" json:"-"`
+	Scope     string    `gorm:"not null;size:50;uniqueIndex:idx_order_sequences_tenant_scope_period" json:"scope"`
+	Period    string    `gorm:"not null;size:20;uniqueIndex:idx_order_sequences_tenant_scope_period" json:"period"`
+	LastValue uint64    `gorm:"not null;default:0" json:"last_value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (OrderSequence) TableName() string {
+	return "order_sequences"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Outlet is a store location. Users and sale orders assigned to an outlet are only
+// visible to users of that outlet unless they hold the outlet.all permission.
+type Outlet struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	TenantID  uint           `gorm:"not null;uniqueIndex:idx_outlets_tenant_code" json:"-"`
+	Code      string         `gorm:"not null;size:20;uniqueIndex:idx_outlets_tenant_code" json:"code"`
+	Name      string         `gorm:"not null;size:255" json:"name"`
+	Address   string         `gorm:"type:text" json:"address"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Outlet) TableName() string {
+	return "outlets"
+}
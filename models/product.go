@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Product is a stocked item. Stock is only changed through stock movements so
+// every change is recorded in the ledger, and CostPrice is the moving average cost
+// of the units on hand. Barcode is an EAN-13 (UPC-A codes are stored in EAN-13 form),
+// an internal code, or the 7-digit prefix and item code of in-store weight or price
+// barcodes, in which case Price is the price per kilogram for weight barcodes. Stock
+// counts whole units, so products SoldByWeight keep no stock and have no stock movements.
+// CostPrice is left out of the JSON so catalog responses don't reveal margins; handlers
+// add it back for callers with product.manage.
+type Product struct {
+	ID             uint             `gorm:"primaryKey" json:"id"`
+	TenantID       uint             `gorm:"not null;uniqueIndex:idx_products_tenant_sku;uniqueIndex:idx_products_tenant_barcode" json:"-"`
+	SKU            string           `gorm:"not null;size:50;uniqueIndex:idx_products_tenant_sku" json:"sku"`
+	Barcode        *string          `gorm:"size:50;uniqueIndex:idx_products_tenant_barcode" json:"barcode"`
+	Name           string           `gorm:"not null;size:255" json:"name"`
+	CategoryID     *uint            `gorm:"index" json:"category_id"`
+	Category       *Category        `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Price          float64          `gorm:"not null;default:0" json:"price"`
+	CostPrice      float64          `gorm:"not null;default:0" json:"-"`
+	Stock          int              `gorm:"not null;default:0" json:"stock"`
+	SoldByWeight   bool             `gorm:"not null;default:false" json:"sold_by_weight"`
+	IsActive       bool             `gorm:"default:true" json:"is_active"`
+	Variants       []ProductVariant `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	ModifierGroups []ModifierGroup  `gorm:"many2many:product_modifier_groups" json:"modifier_groups,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt   `gorm:"index" json:"-"`
+}
+
+func (Product) TableName() string {
+	return "products"
+}
+
+// ProductVariant is a sellable version of a product such as a size or colour, with its
+// own SKU and price. Variants share the stock of their product. A product with active
+// variants can only be sold as one of them.
+type ProductVariant struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	TenantID  uint           `gorm:"not null;uniqueIndex:idx_product_variants_tenant_sku;uniqueIndex:idx_product_variants_tenant_barcode" json:"-"`
+	ProductID uint           `gorm:"not null;index" json:"product_id"`
+	SKU       string         `gorm:"not null;size:50;uniqueIndex:idx_product_variants_tenant_sku" json:"sku"`
+	Barcode   *string        `gorm:"size:50;uniqueIndex:idx_product_variants_tenant_barcode" json:"barcode"`
+	Name      string         `gorm:"not null;size:255" json:"name"`
+	Price     float64        `gorm:"not null;default:0" json:"price"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// Stock movement types
+const (
+	StockMovementPurchaseReceipt = "purchase_receipt"
+	StockMovementSale            = "sale"
+	StockMovementSaleReturn      = "sale_return"
+	StockMovementStockTake       = "stock_take"
+)
+
+// StockMovement is an append-only stock ledger entry. Quantity is positive for
+// incoming and negative for outgoing stock; BalanceAfter is the product stock
+// after the movement.
+type StockMovement struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TenantID      uint      `gorm:"not null;index" json:"-"`
+	ProductID     uint      `gorm:"not null;index" json:"product_id"`
+	Type          string    `gorm:"not null;size:50;index" json:"type"`
+	Quantity      int       `gorm:"not null" json:"quantity"`
+	UnitCost      float64   `gorm:"not null;default:0" json:"unit_cost"`
+	BalanceAfter  int       `gorm:"not null" json:"balance_after"`
+	ReferenceType string    `gorm:"size:50;index:idx_stock_movements_reference" json:"reference_type"`
+	ReferenceID   uint      `gorm:"index:idx_stock_movements_reference" json:"reference_id"`
+	CreatedByID   uint      `gorm:"not null" json:"created_by_id"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
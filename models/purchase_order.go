@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Purchase order statuses
+const (
+	PurchaseOrderOrdered           = "ordered"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+)
+
+// PurchaseOrder is a restocking order placed with a supplier. Goods receipts move it
+// from ordered to partially_received and, once every item is in, to received.
+type PurchaseOrder struct {
+	ID          uint                `gorm:"primaryKey" json:"id"`
+	TenantID    uint                `gorm:"not null;uniqueIndex:idx_purchase_orders_tenant_po_number" json:"-"`
+	PONumber    string              `gorm:"column:po_number;not null;size:50;uniqueIndex:idx_purchase_orders_tenant_po_number" json:"po_number"`
+	SupplierID  uint                `gorm:"not null;index" json:"supplier_id"`
+	Supplier    *Supplier           `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	Status      string              `gorm:"not null;size:30;index" json:"status"`
+	TotalAmount float64             `gorm:"not null;default:0" json:"total_amount"`
+	Notes       string              `gorm:"type:text" json:"notes"`
+	CreatedByID uint                `gorm:"not null" json:"created_by_id"`
+	CreatedBy   *User               `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
+	Items       []PurchaseOrderItem `gorm:"foreignKey:PurchaseOrderID" json:"items,omitempty"`
+	Version     uint                `gorm:"not null;default:1" json:"version"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt      `gorm:"index" json:"-"`
+}
+
+func (PurchaseOrder) TableName() string {
+	return "purchase_orders"
+}
+
+type PurchaseOrderItem struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	TenantID         uint      `gorm:"not null;index" json:"-"`
+	PurchaseOrderID  uint      `gorm:"not null;index" json:"purchase_order_id"`
+	ProductID        uint      `gorm:"not null;index" json:"product_id"`
+	Product          *Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	QuantityOrdered  int       `gorm:"not null" json:"quantity_ordered"`
+	QuantityReceived int       `gorm:"not null;default:0" json:"quantity_received"`
+	UnitCost         float64   `gorm:"not null" json:"unit_cost"`
+	Subtotal         float64   `gorm:"not null" json:"subtotal"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (PurchaseOrderItem) TableName() string {
+	return "purchase_order_items"
+}
+
+// GoodsReceipt records a delivery against a purchase order
+type GoodsReceipt struct {
+	ID              uint               `gorm:"primaryKey" json:"id"`
+	TenantID        uint               `gorm:"not null;index" json:"-"`
+	PurchaseOrderID uint               `gorm:"not null;index" json:"purchase_order_id"`
+	Notes           string             `gorm:"type:text" json:"notes"`
+	ReceivedByID    uint               `gorm:"not null" json:"received_by_id"`
+	ReceivedBy      *User              `gorm:"foreignKey:ReceivedByID" json:"received_by,omitempty"`
+	Items           []GoodsReceiptItem `gorm:"foreignKey:GoodsReceiptID" json:"items,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+func (GoodsReceipt) TableName() string {
+	return "goods_receipts"
+}
+
+type GoodsReceiptItem struct {
+	ID                  uint    `gorm:"primaryKey" json:"id"`
+	TenantID            uint    `gorm:"not null;index" json:"-"`
+	GoodsReceiptID      uint    `gorm:"not null;index" json:"goods_receipt_id"`
+	PurchaseOrderItemID uint    `gorm:"not null;index" json:"purchase_order_item_id"`
+	ProductID           uint    `gorm:"not null" json:"product_id"`
+	Quantity            int     `gorm:"not null" json:"quantity"`
+	UnitCost            float64 `gorm:"not null" json:"unit_cost"`
+}
+
+func (GoodsReceiptItem) TableName() string {
+	return "goods_receipt_items"
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+)
+
+type Permission string
+
+const (
+	PermSaleOrderView   Permission = "sale_order.view"
+	PermSaleOrderCreate Permission = "sale_order.create"
+	PermSaleOrderUpdate Permission = "sale_order.update"
+	PermSaleOrderVoid   Permission = "sale_order.void"
+	PermSaleOrderAll    Permission = "sale_order.all"
+	PermSaleOrderAdjust Permission = "sale_order.adjust"
+	// PermSaleOrderPriceOverride allows selling an item below its catalog price
+	PermSaleOrderPriceOverride Permission = "sale_order.price_override"
+	PermReportView             Permission = "report.view"
+	PermUserManage             Permission = "user.manage"
+	PermOwnerManage            Permission = "owner.manage"
+	PermRoleManage             Permission = "role.manage"
+	PermTerminalManage         Permission = "terminal.manage"
+	PermAuditView              Permission = "audit.view"
+	PermTrashManage            Permission = "trash.manage"
+	PermOutletAll              Permission = "outlet.all"
+	PermOutletManage           Permission = "outlet.manage"
+	PermProductManage          Permission = "product.manage"
+	PermPurchaseManage         Permission = "purchase.manage"
+	PermStockTakeCount         Permission = "stock_take.count"
+	PermStockTakeManage        Permission = "stock_take.manage"
+)
+
+// AllPermissions lists every permission that can be assigned to a role
+var AllPermissions = []Permission{
+	PermSaleOrderView,
+	PermSaleOrderCreate,
+	PermSaleOrderUpdate,
+	PermSaleOrderVoid,
+	PermSaleOrderAll,
+	PermSaleOrderAdjust,
+	PermSaleOrderPriceOverride,
+	PermReportView,
+	PermUserManage,
+	PermOwnerManage,
+	PermRoleManage,
+	PermTerminalManage,
+	PermAuditView,
+	PermTrashManage,
+	PermOutletAll,
+	PermOutletManage,
+	PermProductManage,
+	PermPurchaseManage,
+	PermStockTakeCount,
+	PermStockTakeManage,
+}
+
+// IsValidPermission reports whether p is a known permission
+func IsValidPermission(p Permission) bool {
+	for _, known := range AllPermissions {
+		if known == p {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleDefinition is a role stored in the database together with its permissions.
+// System roles (owner, cashier) cannot be renamed or deleted, and the owner role
+// always holds every permission.
+type RoleDefinition struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	TenantID    uint             `gorm:"not null;uniqueIndex:idx_roles_tenant_name" json:"-"`
+	Name        Role             `gorm:"not null;size:50;uniqueIndex:idx_roles_tenant_name" json:"name"`
+	Description string           `gorm:"size:255" json:"description"`
+	IsSystem    bool             `gorm:"not null;default:false" json:"is_system"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID" json:"permissions,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+func (RoleDefinition) TableName() string {
+	return "roles"
+}
+
+type RolePermission struct {
+	ID         uint       `gorm:"primaryKey" json:"-"`
+	RoleID     uint       `gorm:"not null;uniqueIndex:idx_role_permission" json:"-"`
+	Permission Permission `gorm:"not null;size:100;uniqueIndex:idx_role_permission" json:"permission"`
+}
+
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// DefaultRolePermissions are assigned to the system roles when they are first seeded.
+// Cashiers need a supervisor override to void orders or lower an order's total.
+var DefaultRolePermissions = map[Role][]Permission{
+	RoleOwner: AllPermissions,
+	RoleCashier: {
+		PermSaleOrderView,
+		PermSaleOrderCreate,
+		PermSaleOrderUpdate,
+		PermStockTakeCount,
+	},
+}
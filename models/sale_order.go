@@ -7,35 +7,73 @@ import (
 )
 
 type SaleOrder struct {
-	ID             uint            `gorm:"primaryKey" json:"id"`
-	OrderNumber    string          `gorm:"uniqueIndex;not null;size:50" json:"order_number"`
-	CustomerName   string          `gorm:"size:255;not null" json:"customer_name"`
-	TotalAmount    float64         `gorm:"not null;default:0" json:"total_amount"`
-	Notes          string          `gorm:"type:text" json:"notes"`
-	CreatedByID    uint            `gorm:"not null" json:"created_by_id"`
-	CreatedBy      *User           `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
-	SaleOrderItems []SaleOrderItem `gorm:"foreignKey:SaleOrderID" json:"items,omitempty"`
-	CreatedAt      time.Time       `json:"created_at"`
-	UpdatedAt      time.Time       `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"-"`
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	TenantID        uint            `gorm:"not null;uniqueIndex:idx_sale_orders_tenant_order_number;uniqueIndex:idx_sale_orders_tenant_client_id" json:"-"`
+	OrderNumber     string          `gorm:"not null;size:50;uniqueIndex:idx_sale_orders_tenant_order_number" json:"order_number"`
+	CustomerName    string          `gorm:"size:255;not null" json:"customer_name"`
+	TotalAmount     float64         `gorm:"not null;default:0" json:"total_amount"`
+	Notes           string          `gorm:"type:text" json:"notes"`
+	CreatedByID     uint            `gorm:"not null" json:"created_by_id"`
+	CreatedBy       *User           `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
+	OutletID        *uint           `gorm:"index" json:"outlet_id"`
+	Outlet          *Outlet         `gorm:"foreignKey:OutletID" json:"outlet,omitempty"`
+	TerminalID      *uint           `gorm:"index" json:"terminal_id"`
+	SaleOrderItems  []SaleOrderItem `gorm:"foreignKey:SaleOrderID" json:"items,omitempty"`
+	ApprovedByID    *uint           `json:"approved_by_id"`
+	ApprovedBy      *User           `gorm:"foreignKey:ApprovedByID" json:"approved_by,omitempty"`
+	ApprovalAction  string          `gorm:"size:50" json:"approval_action,omitempty"`
+	ApprovedAt      *time.Time      `json:"approved_at"`
+	ClientID        *string         `gorm:"size:36;uniqueIndex:idx_sale_orders_tenant_client_id" json:"client_id,omitempty"`
+	ClientCreatedAt *time.Time      `json:"client_created_at,omitempty"`
+	Version         uint            `gorm:"not null;default:1" json:"version"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt  `gorm:"index" json:"-"`
 }
 
 func (SaleOrder) TableName() string {
 	return "sale_orders"
 }
 
+// SaleOrderItem is a line of a sale order. UnitCost snapshots the product's cost price
+// for margin reports and is never sent to API clients. ListPrice snapshots the catalog
+// price the item would have sold at, so an overridden UnitPrice shows as a discount. Items of products sold by weight
+// carry the weighed amount in WeightGrams, with UnitPrice and UnitCost for that weight.
 type SaleOrderItem struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	SaleOrderID uint           `gorm:"not null" json:"sale_order_id"`
-	ProductName string         `gorm:"not null;size:255" json:"product_name"`
-	Quantity    int            `gorm:"not null;default:1" json:"quantity"`
-	UnitPrice   float64        `gorm:"not null" json:"unit_price"`
-	Subtotal    float64        `gorm:"not null" json:"subtotal"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uint                    `gorm:"primaryKey" json:"id"`
+	TenantID    uint                    `gorm:"not null;index" json:"-"`
+	SaleOrderID uint                    `gorm:"not null" json:"sale_order_id"`
+	ProductID   *uint                   `gorm:"index" json:"product_id"`
+	ProductName string                  `gorm:"not null;size:255" json:"product_name"`
+	VariantID   *uint                   `json:"variant_id"`
+	VariantName string                  `gorm:"size:255" json:"variant_name,omitempty"`
+	Modifiers   []SaleOrderItemModifier `gorm:"foreignKey:SaleOrderItemID" json:"modifiers,omitempty"`
+	Quantity    int                     `gorm:"not null;default:1" json:"quantity"`
+	WeightGrams *int                    `json:"weight_grams,omitempty"`
+	ListPrice   float64                 `gorm:"not null;default:0" json:"list_price"`
+	UnitPrice   float64                 `gorm:"not null" json:"unit_price"`
+	UnitCost    float64                 `gorm:"not null;default:0" json:"-"`
+	Subtotal    float64                 `gorm:"not null" json:"subtotal"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt          `gorm:"index" json:"-"`
 }
 
 func (SaleOrderItem) TableName() string {
 	return "sale_order_items"
 }
+
+// SaleOrderItemModifier is a modifier picked for a sale order item. Name and price delta
+// are copied so later catalog changes do not alter past orders.
+type SaleOrderItemModifier struct {
+	ID              uint    `gorm:"primaryKey" json:"id"`
+	TenantID        uint    `gorm:"not null;index" json:"-"`
+	SaleOrderItemID uint    `gorm:"not null;index" json:"sale_order_item_id"`
+	ModifierID      uint    `gorm:"not null" json:"modifier_id"`
+	Name            string  `gorm:"not null;size:255" json:"name"`
+	PriceDelta      float64 `gorm:"not null;default:0" json:"price_delta"`
+}
+
+func (SaleOrderItemModifier) TableName() string {
+	return "sale_order_item_modifiers"
+}
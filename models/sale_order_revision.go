@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SaleOrderRevision is a numbered snapshot of a sale order header and its items,
+// taken when the order is created and after every update.
+type SaleOrderRevision struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	TenantID       uint            `gorm:"not null;index" json:"-"`
+	SaleOrderID    uint            `gorm:"not null;uniqueIndex:idx_sale_order_revision" json:"sale_order_id"`
+	RevisionNumber int             `gorm:"not null;uniqueIndex:idx_sale_order_revision" json:"revision_number"`
+	Snapshot       json.RawMessage `gorm:"type:jsonb;not null" json:"snapshot"`
+	CreatedByID    uint            `gorm:"not null" json:"created_by_id"`
+	CreatedBy      *User           `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func (SaleOrderRevision) TableName() string {
+	return "sale_order_revisions"
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SchemaMigration records a one-off data migration that has already run, so
+// it is not applied again on later startups
+type SchemaMigration struct {
+	Name      string    `gorm:"primaryKey;size:100" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Stock take statuses
+const (
+	StockTakeOpen      = "open"
+	StockTakeApproved  = "approved"
+	StockTakeCancelled = "cancelled"
+)
+
+// StockTake is a physical inventory count. The expected quantity of every product is
+// captured when the session starts; staff submit counts while it is open and an
+// approval posts the variances to the stock ledger. A partial unique index allows only
+// one open session per tenant.
+type StockTake struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	TenantID     uint            `gorm:"not null;uniqueIndex:idx_stock_takes_tenant_number;uniqueIndex:idx_stock_takes_tenant_open,where:status = 'open'" json:"-"`
+	Number       string          `gorm:"not null;size:50;uniqueIndex:idx_stock_takes_tenant_number" json:"number"`
+	Status       string          `gorm:"not null;size:20;index" json:"status"`
+	Notes        string          `gorm:"type:text" json:"notes"`
+	CreatedByID  uint            `gorm:"not null" json:"created_by_id"`
+	CreatedBy    *User           `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
+	ApprovedByID *uint           `json:"approved_by_id"`
+	ApprovedBy   *User           `gorm:"foreignKey:ApprovedByID" json:"approved_by,omitempty"`
+	ApprovedAt   *time.Time      `json:"approved_at"`
+	Items        []StockTakeItem `gorm:"foreignKey:StockTakeID" json:"items,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+func (StockTake) TableName() string {
+	return "stock_takes"
+}
+
+// StockTakeItem is one product of a stock take. CountedQuantity and Variance stay
+// empty until the product is counted; uncounted products are not adjusted.
+type StockTakeItem struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	TenantID         uint       `gorm:"not null;index" json:"-"`
+	StockTakeID      uint       `gorm:"not null;uniqueIndex:idx_stock_take_items_product" json:"stock_take_id"`
+	ProductID        uint       `gorm:"not null;uniqueIndex:idx_stock_take_items_product" json:"product_id"`
+	Product          *Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	ExpectedQuantity int        `gorm:"not null" json:"expected_quantity"`
+	CountedQuantity  *int       `json:"counted_quantity"`
+	Variance         *int       `json:"variance"`
+	CountedByID      *uint      `json:"counted_by_id"`
+	CountedAt        *time.Time `json:"counted_at"`
+}
+
+func (StockTakeItem) TableName() string {
+	return "stock_take_items"
+}
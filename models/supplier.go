@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Supplier struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	TenantID    uint           `gorm:"not null;index" json:"-"`
+	Name        string         `gorm:"not null;size:255" json:"name"`
+	ContactName string         `gorm:"size:255" json:"contact_name"`
+	Phone       string         `gorm:"size:50" json:"phone"`
+	Email       string         `gorm:"size:255" json:"email"`
+	Address     string         `gorm:"type:text" json:"address"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Supplier) TableName() string {
+	return "suppliers"
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// DefaultTenantSlug identifies the tenant that owns all data when the deployment runs
+// in single-tenant mode. It is created by the migration.
+const DefaultTenantSlug = "default"
+
+// Tenant is an independent business hosted on the deployment. Every tenant-owned
+// model has a TenantID column that is filled and filtered automatically from the
+// request context (see database.RegisterTenantScope).
+type Tenant struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Slug      string    `gorm:"uniqueIndex;not null;size:63" json:"slug"`
+	Name      string    `gorm:"not null;size:255" json:"name"`
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Tenant) TableName() string {
+	return "tenants"
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Terminal is a registered POS device. Its credential is issued once as
+// "<code>.<secret>" and only the bcrypt hash of the secret is stored.
+type Terminal struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	TenantID       uint           `gorm:"not null;index" json:"-"`
+	Code           string         `gorm:"uniqueIndex;not null;size:50" json:"code"`
+	Name           string         `gorm:"not null;size:255" json:"name"`
+	CredentialHash string         `gorm:"not null" json:"-"`
+	IsActive       bool           `gorm:"default:true" json:"is_active"`
+	LastSeenAt     *time.Time     `json:"last_seen_at"`
+	CreatedByID    uint           `gorm:"not null" json:"created_by_id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Terminal) TableName() string {
+	return "terminals"
+}
@@ -14,17 +14,35 @@ const (
 )
 
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Username  string         `gorm:"uniqueIndex;not null;size:100" json:"username"`
-	Password  string         `gorm:"not null" json:"-"`
-	Name      string         `gorm:"not null;size:255" json:"name"`
-	Role      Role           `gorm:"not null;size:20" json:"role"`
-	IsActive  bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	TenantID           uint           `gorm:"not null;uniqueIndex:idx_users_tenant_username" json:"-"`
+	Username           string         `gorm:"not null;size:100;uniqueIndex:idx_users_tenant_username" json:"username"`
+	Password           string         `gorm:"not null" json:"-"`
+	PIN                string         `gorm:"size:255" json:"-"`
+	Name               string         `gorm:"not null;size:255" json:"name"`
+	Role               Role           `gorm:"not null;size:50" json:"role"`
+	IsActive           bool           `gorm:"default:true" json:"is_active"`
+	MustChangePassword bool           `gorm:"not null;default:false" json:"must_change_password"`
+	OutletID           *uint          `gorm:"index" json:"outlet_id"`
+	Outlet             *Outlet        `gorm:"foreignKey:OutletID" json:"outlet,omitempty"`
+	Version            uint           `gorm:"not null;default:1" json:"version"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
+
+// PasswordHistory keeps previous password hashes to prevent reuse
+type PasswordHistory struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
@@ -1,6 +1,9 @@
 package routes
 
 import (
+	"time"
+
+	"interview-user/config"
 	"interview-user/handlers"
 	"interview-user/middleware"
 	"interview-user/models"
@@ -10,50 +13,262 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(r *gin.Engine, db *gorm.DB, jwtService *utils.JWTService) {
+func SetupRoutes(r *gin.Engine, db *gorm.DB, jwtService *utils.JWTService, cfg *config.Config) {
+	passwordPolicy := utils.NewPasswordPolicy(cfg)
+	orderNumbers := utils.NewOrderNumberFormat(cfg)
+	credentials := utils.NewCredentialThrottle(cfg)
+
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(db, jwtService)
-	saleOrderHandler := handlers.NewSaleOrderHandler(db)
-	userHandler := handlers.NewUserHandler(db)
+	authHandler := handlers.NewAuthHandler(db, jwtService, passwordPolicy, credentials)
+	saleOrderHandler := handlers.NewSaleOrderHandler(db, orderNumbers, credentials)
+	syncHandler := handlers.NewSyncHandler(db, orderNumbers)
+	userHandler := handlers.NewUserHandler(db, passwordPolicy)
+	terminalHandler := handlers.NewTerminalHandler(db)
+	roleHandler := handlers.NewRoleHandler(db)
+	auditLogHandler := handlers.NewAuditLogHandler(db)
+	trashHandler := handlers.NewTrashHandler(db, cfg.SoftDeleteRetentionDays)
+	outletHandler := handlers.NewOutletHandler(db)
+	tenantHandler := handlers.NewTenantHandler(db, passwordPolicy)
+	productHandler := handlers.NewProductHandler(db, utils.NewBarcodeFormat(cfg))
+	categoryHandler := handlers.NewCategoryHandler(db)
+	modifierGroupHandler := handlers.NewModifierGroupHandler(db)
+	menuHandler := handlers.NewMenuHandler(db)
+	reportHandler := handlers.NewReportHandler(db)
+	supplierHandler := handlers.NewSupplierHandler(db)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(db)
+	stockTakeHandler := handlers.NewStockTakeHandler(db)
 
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		utils.OKResponse(c, "Service is healthy", nil)
 	})
 
+	// Tenant provisioning, protected by the platform admin key
+	platform := r.Group("/platform")
+	platform.Use(middleware.PlatformAuthMiddleware(cfg.PlatformAdminKey))
+	{
+		platform.GET("/tenants", tenantHandler.GetAll)
+		platform.POST("/tenants", tenantHandler.Create)
+	}
+
+	// Every other route runs for the tenant resolved from the header, subdomain or token
+	r.Use(middleware.TenantMiddleware(db, cfg.MultiTenant, cfg.TenantBaseDomain))
+
 	// Auth routes (public)
 	auth := r.Group("/auth")
+	auth.Use(middleware.RequireTenant())
 	{
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/pin-login", middleware.TerminalAuthMiddleware(db), authHandler.PINLogin)
 	}
 
 	// Protected routes
 	protected := r.Group("")
-	protected.Use(middleware.AuthMiddleware(jwtService))
+	protected.Use(
+		middleware.AuthMiddleware(jwtService, db),
+		middleware.TerminalIdentityMiddleware(db, cfg.RequireCashierTerminal),
+		middleware.IdempotencyMiddleware(db, time.Duration(cfg.IdempotencyKeyTTL)*time.Hour),
+	)
 	{
 		// Logout (requires auth)
 		protected.POST("/auth/logout", authHandler.Logout)
+		protected.POST("/auth/change-password", authHandler.ChangePassword)
+
+		// Self-service profile - any authenticated user
+		me := protected.Group("/me")
+		{
+			me.GET("", userHandler.GetMe)
+			me.PATCH("", userHandler.UpdateMe)
+			me.POST("/password", authHandler.ChangePassword)
+		}
 
-		// Sale Orders - accessible by both cashier and owner
+		// Offline sync for terminals
+		sync := protected.Group("/sync")
+		{
+			sync.POST("/sale-orders", middleware.RequirePermission(db, models.PermSaleOrderCreate), syncHandler.SyncSaleOrders)
+			sync.GET("/changes", middleware.RequirePermission(db, models.PermSaleOrderView), syncHandler.GetChanges)
+		}
+
+		// Sale Orders
 		saleOrders := protected.Group("/sale-orders")
-		saleOrders.Use(middleware.RBACMiddleware(models.RoleCashier, models.RoleOwner))
 		{
-			saleOrders.GET("", saleOrderHandler.GetAll)
-			saleOrders.GET("/:id", saleOrderHandler.GetByID)
-			saleOrders.POST("", saleOrderHandler.Create)
-			saleOrders.PATCH("/:id", saleOrderHandler.Update)
-			saleOrders.DELETE("/:id", saleOrderHandler.Delete)
+			saleOrders.GET("", middleware.RequirePermission(db, models.PermSaleOrderView), saleOrderHandler.GetAll)
+			saleOrders.GET("/:id", middleware.RequirePermission(db, models.PermSaleOrderView), saleOrderHandler.GetByID)
+			saleOrders.GET("/:id/revisions", middleware.RequirePermission(db, models.PermSaleOrderView), saleOrderHandler.GetRevisions)
+			saleOrders.GET("/:id/revisions/diff", middleware.RequirePermission(db, models.PermSaleOrderView), saleOrderHandler.DiffRevisions)
+			saleOrders.POST("", middleware.RequirePermission(db, models.PermSaleOrderCreate), saleOrderHandler.Create)
+			saleOrders.PATCH("/:id", middleware.RequirePermission(db, models.PermSaleOrderUpdate), saleOrderHandler.Update)
+			saleOrders.POST("/:id/items", middleware.RequirePermission(db, models.PermSaleOrderUpdate), saleOrderHandler.AddItem)
+			saleOrders.PATCH("/:id/items/:itemId", middleware.RequirePermission(db, models.PermSaleOrderUpdate), saleOrderHandler.UpdateItem)
+			saleOrders.DELETE("/:id/items/:itemId", middleware.RequirePermission(db, models.PermSaleOrderUpdate), saleOrderHandler.RemoveItem)
+			// Voiding without sale_order.void needs a supervisor override, checked in the handler
+			saleOrders.DELETE("/:id", middleware.RequirePermission(db, models.PermSaleOrderUpdate), saleOrderHandler.Delete)
+		}
+
+		// User management
+		users := protected.Group("/users")
+		users.Use(middleware.RequirePermission(db, models.PermUserManage))
+		{
+			users.GET("", userHandler.GetAll)
+			users.GET("/:id", userHandler.GetByID)
+			users.PATCH("/:id", userHandler.Update)
+			users.DELETE("/:id", userHandler.Delete)
+			users.PATCH("/:id/role", userHandler.AssignRole)
+		}
+
+		// User Cashier management
+		cashiers := protected.Group("/users/cashier")
+		cashiers.Use(middleware.RequirePermission(db, models.PermUserManage))
+		{
+			cashiers.GET("", userHandler.GetAllCashiers)
+			cashiers.GET("/:id", userHandler.GetCashierByID)
+			cashiers.POST("", userHandler.CreateCashier)
+			cashiers.PATCH("/:id", userHandler.UpdateCashier)
+			cashiers.DELETE("/:id", userHandler.DeleteCashier)
+		}
+
+		// User Owner management
+		owners := protected.Group("/users/owner")
+		owners.Use(middleware.RequirePermission(db, models.PermOwnerManage))
+		{
+			owners.GET("", userHandler.GetAllOwners)
+			owners.GET("/:id", userHandler.GetOwnerByID)
+			owners.POST("", userHandler.CreateOwner)
+			owners.POST("/transfer", userHandler.TransferOwnership)
+			owners.PATCH("/:id", userHandler.UpdateOwner)
+			owners.DELETE("/:id", userHandler.DeleteOwner)
+		}
+
+		// Roles & permissions
+		roles := protected.Group("/roles")
+		roles.Use(middleware.RequirePermission(db, models.PermRoleManage))
+		{
+			roles.GET("", roleHandler.GetAll)
+			roles.GET("/:id", roleHandler.GetByID)
+			roles.POST("", roleHandler.Create)
+			roles.PATCH("/:id", roleHandler.Update)
+			roles.DELETE("/:id", roleHandler.Delete)
+		}
+		protected.GET("/permissions", middleware.RequirePermission(db, models.PermRoleManage), roleHandler.GetPermissions)
+
+		// Audit logs
+		protected.GET("/audit-logs", middleware.RequirePermission(db, models.PermAuditView), auditLogHandler.GetAll)
+
+		// Soft-deleted records
+		trash := protected.Group("/trash")
+		trash.Use(middleware.RequirePermission(db, models.PermTrashManage))
+		{
+			trash.GET("/sale-orders", trashHandler.GetDeletedSaleOrders)
+			trash.POST("/sale-orders/:id/restore", trashHandler.RestoreSaleOrder)
+			trash.GET("/users", trashHandler.GetDeletedUsers)
+			trash.POST("/users/:id/restore", trashHandler.RestoreUser)
+			trash.DELETE("/purge", trashHandler.Purge)
+		}
+
+		// Outlets
+		outlets := protected.Group("/outlets")
+		outlets.Use(middleware.RequirePermission(db, models.PermOutletManage))
+		{
+			outlets.GET("", outletHandler.GetAll)
+			outlets.GET("/:id", outletHandler.GetByID)
+			outlets.POST("", outletHandler.Create)
+			outlets.PATCH("/:id", outletHandler.Update)
+			outlets.DELETE("/:id", outletHandler.Delete)
+		}
+
+		// Products
+		products := protected.Group("/products")
+		{
+			products.GET("", middleware.RequirePermission(db, models.PermSaleOrderView), productHandler.GetAll)
+			products.GET("/lookup", middleware.RequirePermission(db, models.PermSaleOrderView), productHandler.Lookup)
+			products.GET("/:id", middleware.RequirePermission(db, models.PermSaleOrderView), productHandler.GetByID)
+			products.GET("/:id/stock-movements", middleware.RequirePermission(db, models.PermProductManage), productHandler.GetStockMovements)
+			products.POST("", middleware.RequirePermission(db, models.PermProductManage), productHandler.Create)
+			products.PATCH("/:id", middleware.RequirePermission(db, models.PermProductManage), productHandler.Update)
+			products.DELETE("/:id", middleware.RequirePermission(db, models.PermProductManage), productHandler.Delete)
+			products.POST("/:id/variants", middleware.RequirePermission(db, models.PermProductManage), productHandler.CreateVariant)
+			products.PATCH("/:id/variants/:variantId", middleware.RequirePermission(db, models.PermProductManage), productHandler.UpdateVariant)
+			products.DELETE("/:id/variants/:variantId", middleware.RequirePermission(db, models.PermProductManage), productHandler.DeleteVariant)
+			products.PUT("/:id/modifier-groups", middleware.RequirePermission(db, models.PermProductManage), productHandler.SetModifierGroups)
+		}
+
+		// Categories
+		categories := protected.Group("/categories")
+		{
+			categories.GET("", middleware.RequirePermission(db, models.PermSaleOrderView), categoryHandler.GetAll)
+			categories.GET("/:id", middleware.RequirePermission(db, models.PermSaleOrderView), categoryHandler.GetByID)
+			categories.POST("", middleware.RequirePermission(db, models.PermProductManage), categoryHandler.Create)
+			categories.PATCH("/:id", middleware.RequirePermission(db, models.PermProductManage), categoryHandler.Update)
+			categories.DELETE("/:id", middleware.RequirePermission(db, models.PermProductManage), categoryHandler.Delete)
+		}
+
+		// Menu
+		protected.GET("/menu", middleware.RequirePermission(db, models.PermSaleOrderView), menuHandler.GetMenu)
+
+		// Modifier groups
+		modifierGroups := protected.Group("/modifier-groups")
+		{
+			modifierGroups.GET("", middleware.RequirePermission(db, models.PermSaleOrderView), modifierGroupHandler.GetAll)
+			modifierGroups.GET("/:id", middleware.RequirePermission(db, models.PermSaleOrderView), modifierGroupHandler.GetByID)
+			modifierGroups.POST("", middleware.RequirePermission(db, models.PermProductManage), modifierGroupHandler.Create)
+			modifierGroups.PATCH("/:id", middleware.RequirePermission(db, models.PermProductManage), modifierGroupHandler.Update)
+			modifierGroups.DELETE("/:id", middleware.RequirePermission(db, models.PermProductManage), modifierGroupHandler.Delete)
+			modifierGroups.POST("/:id/modifiers", middleware.RequirePermission(db, models.PermProductManage), modifierGroupHandler.CreateModifier)
+			modifierGroups.PATCH("/:id/modifiers/:modifierId", middleware.RequirePermission(db, models.PermProductManage), modifierGroupHandler.UpdateModifier)
+			modifierGroups.DELETE("/:id/modifiers/:modifierId", middleware.RequirePermission(db, models.PermProductManage), modifierGroupHandler.DeleteModifier)
+		}
+
+		// Reports
+		reports := protected.Group("/reports")
+		reports.Use(middleware.RequirePermission(db, models.PermReportView))
+		{
+			reports.GET("/margins", reportHandler.GetMargins)
+		}
+
+		// Suppliers
+		suppliers := protected.Group("/suppliers")
+		suppliers.Use(middleware.RequirePermission(db, models.PermPurchaseManage))
+		{
+			suppliers.GET("", supplierHandler.GetAll)
+			suppliers.GET("/:id", supplierHandler.GetByID)
+			suppliers.POST("", supplierHandler.Create)
+			suppliers.PATCH("/:id", supplierHandler.Update)
+			suppliers.DELETE("/:id", supplierHandler.Delete)
+		}
+
+		// Purchase orders & goods receipts
+		purchaseOrders := protected.Group("/purchase-orders")
+		purchaseOrders.Use(middleware.RequirePermission(db, models.PermPurchaseManage))
+		{
+			purchaseOrders.GET("", purchaseOrderHandler.GetAll)
+			purchaseOrders.GET("/:id", purchaseOrderHandler.GetByID)
+			purchaseOrders.POST("", purchaseOrderHandler.Create)
+			purchaseOrders.PATCH("/:id", purchaseOrderHandler.Update)
+			purchaseOrders.DELETE("/:id", purchaseOrderHandler.Delete)
+			purchaseOrders.GET("/:id/receipts", purchaseOrderHandler.GetReceipts)
+			purchaseOrders.POST("/:id/receipts", purchaseOrderHandler.Receive)
+		}
+
+		// Stock takes: staff count, owners open, approve and cancel sessions
+		stockTakes := protected.Group("/stock-takes")
+		{
+			stockTakes.GET("", middleware.RequirePermission(db, models.PermStockTakeCount), stockTakeHandler.GetAll)
+			stockTakes.GET("/:id", middleware.RequirePermission(db, models.PermStockTakeCount), stockTakeHandler.GetByID)
+			stockTakes.POST("/:id/counts", middleware.RequirePermission(db, models.PermStockTakeCount), stockTakeHandler.SubmitCounts)
+			stockTakes.POST("", middleware.RequirePermission(db, models.PermStockTakeManage), stockTakeHandler.Create)
+			stockTakes.POST("/:id/approve", middleware.RequirePermission(db, models.PermStockTakeManage), stockTakeHandler.Approve)
+			stockTakes.DELETE("/:id", middleware.RequirePermission(db, models.PermStockTakeManage), stockTakeHandler.Cancel)
 		}
 
-		// User Cashier management - owner only
-		users := protected.Group("/users/cashier")
-		users.Use(middleware.RBACMiddleware(models.RoleOwner))
+		// Terminal registration
+		terminals := protected.Group("/terminals")
+		terminals.Use(middleware.RequirePermission(db, models.PermTerminalManage))
 		{
-			users.GET("", userHandler.GetAllCashiers)
-			users.GET("/:id", userHandler.GetCashierByID)
-			users.POST("", userHandler.CreateCashier)
-			users.PATCH("/:id", userHandler.UpdateCashier)
-			users.DELETE("/:id", userHandler.DeleteCashier)
+			terminals.GET("", terminalHandler.GetAll)
+			terminals.GET("/:id", terminalHandler.GetByID)
+			terminals.POST("", terminalHandler.Create)
+			terminals.PATCH("/:id", terminalHandler.Update)
+			terminals.DELETE("/:id", terminalHandler.Delete)
 		}
 	}
 }
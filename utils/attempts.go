@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"interview-user/config"
+)
+
+// AttemptLimiter locks a key out for Lockout once MaxAttempts failures happen within
+// Lockout of the first one. State is kept in memory, like the token blacklist.
+type AttemptLimiter struct {
+	MaxAttempts int
+	Lockout     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*attemptEntry
+}
+
+type attemptEntry struct {
+	failures    int
+	firstFailed time.Time
+	lockedUntil time.Time
+}
+
+func NewAttemptLimiter(maxAttempts int, lockout time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		MaxAttempts: maxAttempts,
+		Lockout:     lockout,
+		entries:     make(map[string]*attemptEntry),
+	}
+}
+
+// Locked reports whether key is locked out and for how much longer
+func (l *AttemptLimiter) Locked(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.entries[key]
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(entry.lockedUntil)
+	return remaining, remaining > 0
+}
+
+// Fail records a failed attempt for key
+func (l *AttemptLimiter) Fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.prune(now)
+
+	entry, ok := l.entries[key]
+	if !ok || now.Sub(entry.firstFailed) > l.Lockout {
+		entry = &attemptEntry{firstFailed: now}
+		l.entries[key] = entry
+	}
+	entry.failures++
+	if entry.failures >= l.MaxAttempts {
+		entry.lockedUntil = now.Add(l.Lockout)
+	}
+}
+
+// Reset forgets the failures of key after a successful attempt
+func (l *AttemptLimiter) Reset(key string) {
+	l.mu.Lock()
+	delete(l.entries, key)
+	l.mu.Unlock()
+}
+
+// prune drops entries whose window and lockout have both passed
+func (l *AttemptLimiter) prune(now time.Time) {
+	for key, entry := range l.entries {
+		if now.After(entry.lockedUntil) && now.Sub(entry.firstFailed) > l.Lockout {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// CredentialThrottle limits PIN and supervisor credential guessing per user and per terminal.
+// A terminal allows more failures than a user since several cashiers share it.
+type CredentialThrottle struct {
+	Users     *AttemptLimiter
+	Terminals *AttemptLimiter
+}
+
+// NewCredentialThrottle builds the credential throttle from configuration
+func NewCredentialThrottle(cfg *config.Config) *CredentialThrottle {
+	lockout := time.Duration(cfg.CredentialLockoutMinutes) * time.Minute
+	return &CredentialThrottle{
+		Users:     NewAttemptLimiter(cfg.UserMaxCredentialAttempts, lockout),
+		Terminals: NewAttemptLimiter(cfg.TerminalMaxCredentialAttempts, lockout),
+	}
+}
+
+func userAttemptKey(tenantID uint, username string) string {
+	return fmt.Sprintf("%d:%s", tenantID, username)
+}
+
+func terminalAttemptKey(terminalID uint) string {
+	return fmt.Sprintf("%d", terminalID)
+}
+
+// Locked reports whether the user or the terminal (0 for none) is locked out and for how long
+func (t *CredentialThrottle) Locked(tenantID uint, username string, terminalID uint) (time.Duration, bool) {
+	if remaining, locked := t.Users.Locked(userAttemptKey(tenantID, username)); locked {
+		return remaining, true
+	}
+	if terminalID != 0 {
+		return t.Terminals.Locked(terminalAttemptKey(terminalID))
+	}
+	return 0, false
+}
+
+// Fail records a wrong credential for the user and the terminal (0 for none)
+func (t *CredentialThrottle) Fail(tenantID uint, username string, terminalID uint) {
+	t.Users.Fail(userAttemptKey(tenantID, username))
+	if terminalID != 0 {
+		t.Terminals.Fail(terminalAttemptKey(terminalID))
+	}
+}
+
+// Succeed clears the failures of the user
+func (t *CredentialThrottle) Succeed(tenantID uint, username string) {
+	t.Users.Reset(userAttemptKey(tenantID, username))
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"interview-user/config"
+)
+
+// Barcode symbologies recognised by NormalizeBarcode
+const (
+	BarcodeEAN13    = "ean13"
+	BarcodeUPCA     = "upca"
+	BarcodeInternal = "internal"
+)
+
+var ErrInvalidBarcodeChecksum = errors.New("invalid barcode check digit")
+
+// NormalizeBarcode trims code and validates the check digit of EAN-13 and UPC-A codes.
+// UPC-A codes are returned as their 13-digit EAN form so both spellings of a code
+// match the same product. Any other code is treated as an internal barcode.
+func NormalizeBarcode(code string) (string, string, error) {
+	code = strings.TrimSpace(code)
+	if !isDigits(code) || (len(code) != 12 && len(code) != 13) {
+		return code, BarcodeInternal, nil
+	}
+
+	if !validGTINCheckDigit(code) {
+		return "", "", ErrInvalidBarcodeChecksum
+	}
+	if len(code) == 12 {
+		return "0" + code, BarcodeUPCA, nil
+	}
+	return code, BarcodeEAN13, nil
+}
+
+// validGTINCheckDigit checks the last digit of a GTIN: weighting the other digits
+// 3, 1, 3, ... from the right, the sum including the check digit is a multiple of 10
+func validGTINCheckDigit(code string) bool {
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (sum+int(code[len(code)-1]-'0'))%10 == 0
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// VariableMeasure is the content of an in-store EAN-13 barcode laid out as
+// 2P IIIII VVVVV C: prefix, item code, value and check digit
+type VariableMeasure struct {
+	ItemCode string // prefix and item code, the barcode stored on the product
+	Weight   bool   // the value is a weight in grams rather than a price
+	Value    int
+}
+
+// BarcodeFormat tells which in-store prefixes carry a weight and which a price
+type BarcodeFormat struct {
+	WeightPrefixes []string
+	PricePrefixes  []string
+}
+
+// NewBarcodeFormat builds the barcode format from configuration
+func NewBarcodeFormat(cfg *config.Config) BarcodeFormat {
+	return BarcodeFormat{
+		WeightPrefixes: cfg.BarcodeWeightPrefixes,
+		PricePrefixes:  cfg.BarcodePricePrefixes,
+	}
+}
+
+// ParseVariableMeasure decodes a normalized EAN-13 code whose prefix is configured
+// as a weight or price prefix
+func (f BarcodeFormat) ParseVariableMeasure(code string) (VariableMeasure, bool) {
+	if len(code) != 13 || !isDigits(code) {
+		return VariableMeasure{}, false
+	}
+
+	prefix := code[:2]
+	measure := VariableMeasure{ItemCode: code[:7]}
+	switch {
+	case containsString(f.WeightPrefixes, prefix):
+		measure.Weight = true
+	case containsString(f.PricePrefixes, prefix):
+	default:
+		return VariableMeasure{}, false
+	}
+
+	measure.Value, _ = strconv.Atoi(code[7:12])
+	return measure, true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeBarcode(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		want      string
+		symbology string
+		err       error
+	}{
+		{"ean13", "4006381333931", "4006381333931", BarcodeEAN13, nil},
+		{"ean13 other", "5901234123457", "5901234123457", BarcodeEAN13, nil},
+		{"ean13 trimmed", "  4006381333931\n", "4006381333931", BarcodeEAN13, nil},
+		{"ean13 bad check digit", "4006381333932", "", "", ErrInvalidBarcodeChecksum},
+		{"ean13 transposed digits", "4006383133931", "", "", ErrInvalidBarcodeChecksum},
+		{"upca", "036000291452", "0036000291452", BarcodeUPCA, nil},
+		{"upca other", "012345678905", "0012345678905", BarcodeUPCA, nil},
+		{"upca bad check digit", "036000291453", "", "", ErrInvalidBarcodeChecksum},
+		{"in-store weight", "2112345012506", "2112345012506", BarcodeEAN13, nil},
+		{"internal letters", "SKU-001", "SKU-001", BarcodeInternal, nil},
+		{"internal short digits", "2112345", "2112345", BarcodeInternal, nil},
+		{"internal 14 digits", "40063813339310", "40063813339310", BarcodeInternal, nil},
+		{"internal 13 chars with letter", "400638133393A", "400638133393A", BarcodeInternal, nil},
+		{"empty", "   ", "", BarcodeInternal, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, symbology, err := NormalizeBarcode(tt.code)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NormalizeBarcode(%q) error = %v, want %v", tt.code, err, tt.err)
+			}
+			if got != tt.want || symbology != tt.symbology {
+				t.Errorf("NormalizeBarcode(%q) = %q, %q, want %q, %q", tt.code, got, symbology, tt.want, tt.symbology)
+			}
+		})
+	}
+}
+
+func TestValidGTINCheckDigit(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"5901234123457", true},
+		{"0036000291452", true},
+		{"036000291452", true},
+		{"012345678905", true},
+		{"2512345004509", true},
+		{"0000000000000", true},
+		{"4006381333930", false},
+		{"5901234123458", false},
+		{"036000291450", false},
+		{"012345678900", false},
+	}
+
+	for _, tt := range tests {
+		if got := validGTINCheckDigit(tt.code); got != tt.want {
+			t.Errorf("validGTINCheckDigit(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestParseVariableMeasure(t *testing.T) {
+	format := BarcodeFormat{
+		WeightPrefixes: []string{"20", "21", "22", "23", "24"},
+		PricePrefixes:  []string{"25", "26", "27", "28", "29"},
+	}
+
+	tests := []struct {
+		name string
+		code string
+		want VariableMeasure
+		ok   bool
+	}{
+		{"weight 1.250 kg", "2112345012506", VariableMeasure{ItemCode: "2112345", Weight: true, Value: 1250}, true},
+		{"weight zero", "2200000000002", VariableMeasure{ItemCode: "2200000", Weight: true, Value: 0}, true},
+		{"price 450", "2512345004509", VariableMeasure{ItemCode: "2512345", Value: 450}, true},
+		{"price max value", "2800001999994", VariableMeasure{ItemCode: "2800001", Value: 99999}, true},
+		{"regular ean13", "4006381333931", VariableMeasure{}, false},
+		{"unconfigured prefix", "0036000291452", VariableMeasure{}, false},
+		{"too short", "211234501250", VariableMeasure{}, false},
+		{"not digits", "21123450125A6", VariableMeasure{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := format.ParseVariableMeasure(tt.code)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ParseVariableMeasure(%q) = %+v, %v, want %+v, %v", tt.code, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
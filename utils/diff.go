@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// FieldChange holds the old and new value of a changed field
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// DiffJSON compares the JSON representation of before and after and returns the
+// top-level fields whose values differ. A nil side is treated as an empty object,
+// so a create lists every field and a delete lists every removed field.
+func DiffJSON(before, after interface{}) (map[string]FieldChange, error) {
+	beforeMap, err := toJSONMap(before)
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := toJSONMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(beforeMap)+len(afterMap))
+	for k := range beforeMap {
+		keys[k] = true
+	}
+	for k := range afterMap {
+		keys[k] = true
+	}
+
+	changes := make(map[string]FieldChange)
+	for k := range keys {
+		b, a := beforeMap[k], afterMap[k]
+		if !reflect.DeepEqual(b, a) {
+			changes[k] = FieldChange{Before: b, After: a}
+		}
+	}
+	return changes, nil
+}
+
+func toJSONMap(v interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if v == nil {
+		return result, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return result, nil
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
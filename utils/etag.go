@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetETag exposes a resource version as a strong ETag
+func SetETag(c *gin.Context, version uint) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// CheckIfMatch requires an If-Match header matching version. It writes a 428 response
+// when the header is missing and a 412 response when it doesn't match.
+func CheckIfMatch(c *gin.Context, version uint) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		PreconditionRequiredResponse(c, "If-Match header is required")
+		return false
+	}
+
+	expected := strconv.FormatUint(uint64(version), 10)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		tag = strings.TrimPrefix(tag, "W/")
+		if strings.Trim(tag, `"`) == expected {
+			return true
+		}
+	}
+
+	PreconditionFailedResponse(c, "Resource has been modified, reload and try again")
+	return false
+}
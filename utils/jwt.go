@@ -10,36 +10,58 @@ import (
 )
 
 type JWTClaims struct {
-	UserID   uint        `json:"user_id"`
-	Username string      `json:"username"`
-	Role     models.Role `json:"role"`
+	UserID             uint        `json:"user_id"`
+	TenantID           uint        `json:"tenant_id"`
+	Username           string      `json:"username"`
+	Role               models.Role `json:"role"`
+	TerminalID         uint        `json:"terminal_id,omitempty"`
+	OutletID           uint        `json:"outlet_id,omitempty"`
+	MustChangePassword bool        `json:"must_change_password,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type JWTService struct {
-	SecretKey string
-	ExpiryHrs int
+	SecretKey     string
+	ExpiryHrs     int
+	PINExpiryMins int
 }
 
-func NewJWTService(secretKey string, expiryHrs int) *JWTService {
+func NewJWTService(secretKey string, expiryHrs int, pinExpiryMins int) *JWTService {
 	return &JWTService{
-		SecretKey: secretKey,
-		ExpiryHrs: expiryHrs,
+		SecretKey:     secretKey,
+		ExpiryHrs:     expiryHrs,
+		PINExpiryMins: pinExpiryMins,
 	}
 }
 
 func (j *JWTService) GenerateToken(user *models.User) (string, error) {
+	return j.generateToken(user, 0, time.Duration(j.ExpiryHrs)*time.Hour)
+}
+
+// GeneratePINToken issues a short-lived token bound to the terminal the PIN login came from
+func (j *JWTService) GeneratePINToken(user *models.User, terminalID uint) (string, error) {
+	return j.generateToken(user, terminalID, time.Duration(j.PINExpiryMins)*time.Minute)
+}
+
+func (j *JWTService) generateToken(user *models.User, terminalID uint, expiry time.Duration) (string, error) {
 	claims := JWTClaims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:             user.ID,
+		TenantID:           user.TenantID,
+		Username:           user.Username,
+		Role:               user.Role,
+		TerminalID:         terminalID,
+		MustChangePassword: user.MustChangePassword,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(j.ExpiryHrs) * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
+	if user.OutletID != nil {
+		claims.OutletID = *user.OutletID
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(j.SecretKey))
 }
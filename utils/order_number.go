@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"interview-user/config"
+)
+
+// Order number sequence reset periods
+const (
+	OrderNumberResetNever   = "never"
+	OrderNumberResetYearly  = "yearly"
+	OrderNumberResetMonthly = "monthly"
+	OrderNumberResetDaily   = "daily"
+)
+
+var orderNumberToken = regexp.MustCompile(`\{(STORE|YYYY|YY|MM|DD|SEQ(?::(\d+))?)\}`)
+
+// OrderNumberFormat renders order numbers from a template such as INV/{STORE}/{YYYY}/{SEQ:6}
+type OrderNumberFormat struct {
+	Template string
+	Reset    string
+}
+
+// NewOrderNumberFormat builds the order number format from configuration
+func NewOrderNumberFormat(cfg *config.Config) OrderNumberFormat {
+	return OrderNumberFormat{
+		Template: cfg.OrderNumberTemplate,
+		Reset:    cfg.OrderNumberReset,
+	}
+}
+
+// PerStore reports whether every store numbers its orders from its own sequence,
+// which is the case when the store code is part of the number
+func (f OrderNumberFormat) PerStore() bool {
+	return strings.Contains(f.Template, "{STORE}")
+}
+
+// Period returns the sequence period t falls in; the sequence restarts at 1 in every period
+func (f OrderNumberFormat) Period(t time.Time) string {
+	switch f.Reset {
+	case OrderNumberResetYearly:
+		return t.Format("2006")
+	case OrderNumberResetMonthly:
+		return t.Format("2006-01")
+	case OrderNumberResetDaily:
+		return t.Format("2006-01-02")
+	default:
+		return ""
+	}
+}
+
+// Format renders the order number for sequence value seq issued at t by store
+func (f OrderNumberFormat) Format(t time.Time, store string, seq uint64) string {
+	return orderNumberToken.ReplaceAllStringFunc(f.Template, func(token string) string {
+		match := orderNumberToken.FindStringSubmatch(token)
+		switch match[1] {
+		case "STORE":
+			return store
+		case "YYYY":
+			return t.Format("2006")
+		case "YY":
+			return t.Format("06")
+		case "MM":
+			return t.Format("01")
+		case "DD":
+			return t.Format("02")
+		default:
+			width, _ := strconv.Atoi(match[2])
+			return fmt.Sprintf("%0*d", width, seq)
+		}
+	})
+}
@@ -74,6 +74,26 @@ func NotFoundResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusNotFound, message)
 }
 
+// ConflictResponse returns a 409 Conflict response
+func ConflictResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusConflict, message)
+}
+
+// PreconditionFailedResponse returns a 412 Precondition Failed response
+func PreconditionFailedResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusPreconditionFailed, message)
+}
+
+// PreconditionRequiredResponse returns a 428 Precondition Required response
+func PreconditionRequiredResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusPreconditionRequired, message)
+}
+
+// TooManyRequestsResponse returns a 429 Too Many Requests response
+func TooManyRequestsResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusTooManyRequests, message)
+}
+
 // InternalServerErrorResponse returns a 500 Internal Server Error response
 func InternalServerErrorResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusInternalServerError, message)
@@ -100,6 +120,8 @@ func formatValidationError(e validator.FieldError) string {
 		return field + " is required"
 	case "email":
 		return field + " must be a valid email address"
+	case "numeric":
+		return field + " must contain digits only"
 	case "min":
 		return field + " must be at least " + e.Param() + " characters"
 	case "max":